@@ -0,0 +1,216 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aidenwang9867/depdiffvis/options"
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	"github.com/ossf/scorecard/v4/log"
+)
+
+const (
+	// envVarGitHubActions is set to "true" when running in GitHub Actions.
+	envVarGitHubActions = "GITHUB_ACTIONS"
+	// envVarGitHubOutput points to the file to set the outputs of the step in.
+	envVarGitHubOutput = "GITHUB_OUTPUT"
+	// envVarGitHubStepSummary points to the file of the markdown summary of the step.
+	envVarGitHubStepSummary = "GITHUB_STEP_SUMMARY"
+	// envVarRunnerTemp points to a temporary directory of the runner, cleaned up after the job.
+	envVarRunnerTemp = "RUNNER_TEMP"
+	// envVarGitHubAuthToken is the environment variable of the access token which the GitHub clients use.
+	envVarGitHubAuthToken = "GITHUB_AUTH_TOKEN"
+
+	// Inputs of the action, which GitHub passes as INPUT_<NAME> environment variables.
+	inputOwnerRepo        = "INPUT_OWNER_REPO"
+	inputBase             = "INPUT_BASE"
+	inputHead             = "INPUT_HEAD"
+	inputChecksToRun      = "INPUT_CHECKS_TO_RUN"
+	inputChangeTypesToRun = "INPUT_CHANGE_TYPES_TO_RUN"
+	inputDryRun           = "INPUT_DRY_RUN"
+	inputPullRequest      = "INPUT_PULL_REQUEST"
+	inputGitHubToken      = "INPUT_GITHUB_TOKEN"
+	inputCheckRun         = "INPUT_CHECK_RUN"
+)
+
+// isGitHubActions determines if dependency-diff runs in GitHub Actions.
+func isGitHubActions() bool {
+	return os.Getenv(envVarGitHubActions) == "true"
+}
+
+// parseListInput parses a list input of the action, whose items are separated by commas, spaces or new lines,
+// optionally in brackets and quotes as in ["added", "updated"].
+func parseListInput(value string) []string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	items := []string{}
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	}) {
+		if item = strings.Trim(item, `"'`); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// applyActionInputs sets the options to the inputs of the action, unless they are given by flags, as reported
+// by flagChanged, or by environment variables. GitHub passes the inputs as environment variables, so they take
+// precedence over the config file as well, and are applied after it. Empty inputs, such as an empty list of
+// checks by default, leave the options as they are. The inputs which have no options, such as the pull request
+// to comment, set their environment variables instead.
+func applyActionInputs(opts *options.Options, flagChanged func(name string) bool) {
+	isSet := func(flag, envVar string) bool {
+		_, found := os.LookupEnv(envVar)
+		return (flag != "" && flagChanged(flag)) || (envVar != "" && found)
+	}
+	setEnv := func(envVar string) func(value string) {
+		// Setting an environment variable only fails on an invalid name.
+		return func(v string) { _ = os.Setenv(envVar, v) }
+	}
+	for _, in := range []struct {
+		input, flag, envVar string
+		apply               func(value string)
+	}{
+		{inputOwnerRepo, options.FlagRepo, "", func(v string) { opts.Repo = v }},
+		// Base and head are given by the commit flag.
+		{inputBase, options.FlagCommit, options.EnvVarDepdiffBase, func(v string) { opts.Base = v }},
+		{inputHead, options.FlagCommit, options.EnvVarDepdiffHead, func(v string) { opts.Head = v }},
+		{inputChecksToRun, options.FlagChecks, "", func(v string) {
+			if checks := parseListInput(v); len(checks) != 0 {
+				opts.ChecksToRun = checks
+			}
+		}},
+		{inputChangeTypesToRun, options.FlagChangeTypes, options.EnvVarDepdiffChangeTypes, func(v string) {
+			if changeTypes := parseListInput(v); len(changeTypes) != 0 {
+				opts.ChangeTypes = changeTypes
+			}
+		}},
+		{inputDryRun, options.FlagDryRun, options.EnvVarDepdiffDryRun, func(v string) {
+			if dryRun, err := strconv.ParseBool(v); err == nil {
+				opts.DryRun = dryRun
+			}
+		}},
+		{inputPullRequest, "", envVarPullRequest, setEnv(envVarPullRequest)},
+		{inputGitHubToken, "", envVarGitHubAuthToken, setEnv(envVarGitHubAuthToken)},
+		{inputCheckRun, "", envVarCheckRun, setEnv(envVarCheckRun)},
+	} {
+		if value := strings.TrimSpace(os.Getenv(in.input)); value != "" && !isSet(in.flag, in.envVar) {
+			in.apply(value)
+		}
+	}
+}
+
+// actionOutput is an output of the action.
+type actionOutput struct {
+	name, value string
+}
+
+// writeActionResults writes the markdown report to the step summary, and sets the outputs of the step: the
+// markdown report, the path of the results as JSON, the policy verdict and the counts of changes.
+func writeActionResults(
+	results []pkg.DependencyCheckResult, logLevel log.Level, checkDocs docs.Doc,
+	policy *pkg.DependencydiffPolicy, evaluation *pkg.PolicyEvaluation,
+) error {
+	summaryPath, path := os.Getenv(envVarGitHubStepSummary), os.Getenv(envVarGitHubOutput)
+	if summaryPath == "" && path == "" {
+		return nil
+	}
+	// The report is rendered once within the size of a comment, as the output is meant to be commented,
+	// which is well within the size of a step summary.
+	markdown, err := SprintDependencyChecksToMarkdown(results, logLevel, checkDocs, policy, markdownLimit{
+		maxBytes: maxCommentBytes,
+	})
+	if err != nil {
+		return fmt.Errorf("error formatting the results as markdown: %w", err)
+	}
+	if summaryPath != "" {
+		if err := appendToFile(summaryPath, orNoChanges(*markdown)); err != nil {
+			return fmt.Errorf("error writing the step summary: %w", err)
+		}
+	}
+	if path == "" {
+		return nil
+	}
+	jsonPath := filepath.Join(resultsDir(), "depdiff.json")
+	f, err := os.Create(jsonPath)
+	if err != nil {
+		return fmt.Errorf("error creating the JSON results: %w", err)
+	}
+	defer f.Close()
+	if err := pkg.DependencydiffResultsAsJSON(results, logLevel, checkDocs, policy, f); err != nil {
+		return fmt.Errorf("error writing the JSON results: %w", err)
+	}
+	counts := map[pkg.ChangeType]int{}
+	for _, c := range pkg.PairDependencyChanges(results) {
+		counts[c.ChangeType]++
+	}
+	outputs := []actionOutput{
+		{name: "depdiff_md", value: orNoChanges(*markdown)},
+		{name: "depdiff_json", value: jsonPath},
+		{name: "verdict", value: string(evaluation.Verdict)},
+		{name: "added_count", value: fmt.Sprintf("%d", counts[pkg.Added])},
+		{name: "updated_count", value: fmt.Sprintf("%d", counts[pkg.Updated])},
+		{name: "removed_count", value: fmt.Sprintf("%d", counts[pkg.Removed])},
+		{name: "violations_count", value: fmt.Sprintf("%d", len(evaluation.Violations))},
+	}
+	if err := writeOutputs(path, outputs); err != nil {
+		return fmt.Errorf("error setting the outputs: %w", err)
+	}
+	return nil
+}
+
+// resultsDir returns the directory of the results files set as outputs. The temporary directory of the runner
+// is shared with the later steps of the job: it is mounted at /home/runner/work/_temp in the container of the
+// action, which is the value of RUNNER_TEMP there. The system temporary directory is used outside of a runner.
+func resultsDir() string {
+	if dir := os.Getenv(envVarRunnerTemp); dir != "" {
+		return dir
+	}
+	return os.TempDir()
+}
+
+// writeOutputs appends outputs to the output file of the step. Every value is delimited by a random delimiter
+// which it does not contain, so that multiline values are supported.
+func writeOutputs(path string, outputs []actionOutput) error {
+	var sb strings.Builder
+	for _, o := range outputs {
+		delimiter, err := outputDelimiter(o.value)
+		if err != nil {
+			return err
+		}
+		sb.WriteString(fmt.Sprintf("%s<<%s\n%s\n%s\n", o.name, delimiter, o.value, delimiter))
+	}
+	return appendToFile(path, sb.String())
+}
+
+func outputDelimiter(value string) (string, error) {
+	for {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("error generating an output delimiter: %w", err)
+		}
+		delimiter := "ghadelimiter_" + hex.EncodeToString(b)
+		if !strings.Contains(value, delimiter) {
+			return delimiter, nil
+		}
+	}
+}
+
+func appendToFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aidenwang9867/depdiffvis/options"
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	"github.com/ossf/scorecard/v4/log"
+)
+
+func TestParseListInput(t *testing.T) {
+	t.Parallel()
+	//nolint
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{name: "empty", value: "", want: []string{}},
+		{name: "empty brackets", value: "[]", want: []string{}},
+		{name: "json array", value: `["added", "updated"]`, want: []string{"added", "updated"}},
+		{name: "comma separated", value: "License,Code-Review", want: []string{"License", "Code-Review"}},
+		{name: "multiline", value: "added\nremoved\n", want: []string{"added", "removed"}},
+		{name: "single quotes", value: "['added' , 'removed']", want: []string{"added", "removed"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := parseListInput(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseListInput(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteOutputs(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "output")
+	outputs := []actionOutput{
+		{name: "verdict", value: "pass"},
+		{name: "depdiff_md", value: "# Report\n\nline"},
+	}
+	if err := writeOutputs(path, outputs); err != nil {
+		t.Fatalf("writeOutputs: %v", err)
+	}
+	got := readOutputs(t, path)
+	for _, o := range outputs {
+		if got[o.name] != o.value {
+			t.Errorf("output %s = %q, want %q", o.name, got[o.name], o.value)
+		}
+	}
+}
+
+// readOutputs parses the delimited outputs of an output file of a step.
+func readOutputs(t *testing.T, path string) map[string]string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	got := map[string]string{}
+	for i := 0; i < len(lines); i++ {
+		name, delimiter, ok := strings.Cut(lines[i], "<<")
+		if !ok {
+			t.Fatalf("line %q is not a delimited output", lines[i])
+		}
+		value := []string{}
+		for i++; i < len(lines) && lines[i] != delimiter; i++ {
+			value = append(value, lines[i])
+		}
+		if i == len(lines) {
+			t.Fatalf("output %s is not terminated by %s", name, delimiter)
+		}
+		got[name] = strings.Join(value, "\n")
+	}
+	return got
+}
+
+//nolint:paralleltest
+func TestWriteActionResults(t *testing.T) {
+	// Cannot run parallel tests because of the ENV variables.
+	dir := t.TempDir()
+	summaryPath, outputPath, runnerTemp := filepath.Join(dir, "summary"), filepath.Join(dir, "output"), t.TempDir()
+	t.Setenv(envVarGitHubStepSummary, summaryPath)
+	t.Setenv(envVarGitHubOutput, outputPath)
+	t.Setenv(envVarRunnerTemp, runnerTemp)
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	added, version := pkg.Added, "1.0.0"
+	results := []pkg.DependencyCheckResult{{Name: "dep", ChangeType: &added, Version: &version}}
+	evaluation := &pkg.PolicyEvaluation{Verdict: pkg.VerdictPass}
+	if err := writeActionResults(results, log.InfoLevel, checkDocs, nil, evaluation); err != nil {
+		t.Fatalf("writeActionResults: %v", err)
+	}
+	outputs := readOutputs(t, outputPath)
+	summary, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatalf("os.ReadFile: %v", err)
+	}
+	if outputs["depdiff_md"] == "" || string(summary) != outputs["depdiff_md"] {
+		t.Errorf("got step summary %q, want the markdown output %q", summary, outputs["depdiff_md"])
+	}
+	if want := filepath.Join(runnerTemp, "depdiff.json"); outputs["depdiff_json"] != want {
+		t.Errorf("output depdiff_json = %q, want %q", outputs["depdiff_json"], want)
+	}
+	if _, err := os.Stat(outputs["depdiff_json"]); err != nil {
+		t.Errorf("os.Stat: %v", err)
+	}
+	if outputs["verdict"] != "pass" || outputs["added_count"] != "1" || outputs["removed_count"] != "0" {
+		t.Errorf("got outputs %v, want a passing verdict with 1 added dependency", outputs)
+	}
+}
+
+//nolint:paralleltest
+func TestResultsDir(t *testing.T) {
+	// Cannot run parallel tests because of the ENV variables.
+	t.Setenv(envVarRunnerTemp, "/runner/temp")
+	if got := resultsDir(); got != "/runner/temp" {
+		t.Errorf("resultsDir() = %q, want RUNNER_TEMP", got)
+	}
+	t.Setenv(envVarRunnerTemp, "")
+	if got := resultsDir(); got != os.TempDir() {
+		t.Errorf("resultsDir() = %q, want %q", got, os.TempDir())
+	}
+}
+
+//nolint:paralleltest
+func TestApplyActionInputs_EnvVars(t *testing.T) {
+	// Cannot run parallel tests because of the ENV variables.
+	// The variables are restored after the test, which unsets them for it.
+	t.Setenv(envVarPullRequest, "")
+	os.Unsetenv(envVarPullRequest)
+	t.Setenv(envVarCheckRun, "")
+	os.Unsetenv(envVarCheckRun)
+	t.Setenv(envVarGitHubAuthToken, "env-token")
+	t.Setenv(inputPullRequest, "42")
+	t.Setenv(inputGitHubToken, "input-token")
+	t.Setenv(inputCheckRun, "true")
+
+	applyActionInputs(options.New(), func(string) bool { return false })
+	// The inputs set the environment variables, unless they are already set.
+	if got := os.Getenv(envVarPullRequest); got != "42" {
+		t.Errorf("got %s %q, want the input %q", envVarPullRequest, got, "42")
+	}
+	if got := os.Getenv(envVarCheckRun); got != "true" {
+		t.Errorf("got %s %q, want the input %q", envVarCheckRun, got, "true")
+	}
+	if got := os.Getenv(envVarGitHubAuthToken); got != "env-token" {
+		t.Errorf("got %s %q, want the environment variable %q", envVarGitHubAuthToken, got, "env-token")
+	}
+}
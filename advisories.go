@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/aidenwang9867/depdiffvis/osv"
+	"github.com/google/go-github/v38/github"
+)
+
+// osvToAdvisoryEcosystem maps the OSV ecosystems to those of the GitHub Advisory Database.
+var osvToAdvisoryEcosystem = map[ecosystem]string{
+	ecosystemGo:        "go",
+	ecosystemNpm:       "npm",
+	ecosystemCrates:    "rust",
+	ecosystemPyPI:      "pip",
+	ecosystemMaven:     "maven",
+	ecosystemPackagist: "composer",
+	ecosystemRubyGems:  "rubygems",
+	ecosystemNuGet:     "nuget",
+}
+
+// advisory is a security advisory of the GitHub Advisory Database, as returned by the global security
+// advisories API: https://docs.github.com/en/rest/security-advisories/global-advisories.
+type advisory struct {
+	GHSAID          string                  `json:"ghsa_id"`
+	Vulnerabilities []advisoryVulnerability `json:"vulnerabilities"`
+}
+
+// advisoryVulnerability is a package affected by an advisory.
+type advisoryVulnerability struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	// VulnerableVersionRange is a list of comma-separated constraints, such as ">= 1.0.0, < 1.2.3".
+	VulnerableVersionRange string  `json:"vulnerable_version_range"`
+	FirstPatchedVersion    *string `json:"first_patched_version"`
+}
+
+// advisoryFixes is a fix source of the first patched versions of the packages affected by GHSA advisories,
+// which are reported by the GitHub Dependency Review API without them. Every advisory is fetched once.
+type advisoryFixes struct {
+	ctx        context.Context
+	client     *github.Client
+	advisories map[string]*advisory
+}
+
+var _ osv.FixSource = (*advisoryFixes)(nil)
+
+func newAdvisoryFixes(ctx context.Context, client *github.Client) *advisoryFixes {
+	return &advisoryFixes{ctx: ctx, client: client, advisories: map[string]*advisory{}}
+}
+
+// FixedVersion returns the first patched version of the package version affected by the advisory, or an empty
+// string if the vulnerability is not a GHSA advisory, or the advisory does not affect the version or has no fix.
+func (a *advisoryFixes) FixedVersion(id, eco, name, version string) (string, error) {
+	advisoryEcosystem, found := osvToAdvisoryEcosystem[ecosystem(eco)]
+	if !found || !strings.HasPrefix(id, "GHSA-") {
+		return "", nil
+	}
+	adv, err := a.advisory(id)
+	if err != nil || adv == nil {
+		return "", err
+	}
+	for _, v := range adv.Vulnerabilities {
+		if v.FirstPatchedVersion == nil || v.Package.Ecosystem != advisoryEcosystem ||
+			!strings.EqualFold(v.Package.Name, name) {
+			continue
+		}
+		if inVersionRange(eco, version, v.VulnerableVersionRange) {
+			return *v.FirstPatchedVersion, nil
+		}
+	}
+	return "", nil
+}
+
+// advisory fetches an advisory by its GHSA ID, which is nil if the advisory is not found, e.g. withdrawn.
+func (a *advisoryFixes) advisory(id string) (*advisory, error) {
+	if adv, found := a.advisories[id]; found {
+		return adv, nil
+	}
+	req, err := a.client.NewRequest("GET", path.Join("advisories", id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("request for advisory %s failed with %w", id, err)
+	}
+	adv := &advisory{}
+	if _, err := a.client.Do(a.ctx, req, adv); err != nil {
+		var errResp *github.ErrorResponse
+		if !errors.As(err, &errResp) || errResp.Response == nil || errResp.Response.StatusCode != http.StatusNotFound {
+			return nil, fmt.Errorf("error fetching advisory %s: %w", id, err)
+		}
+		adv = nil
+	}
+	a.advisories[id] = adv
+	return adv, nil
+}
+
+// inVersionRange checks whether the version satisfies all the constraints of a vulnerable version range of
+// the GitHub Advisory Database, such as ">= 1.0.0, < 1.2.3" or "= 1.0.0". Versions are compared as in the
+// OSV ecosystem.
+func inVersionRange(eco, version, versionRange string) bool {
+	for _, constraint := range strings.Split(versionRange, ",") {
+		constraint = strings.TrimSpace(constraint)
+		satisfied := false
+		// The two-character operators must be matched first.
+		for _, op := range []string{">=", "<=", ">", "<", "="} {
+			if !strings.HasPrefix(constraint, op) {
+				continue
+			}
+			c := osv.Compare(eco, version, strings.TrimSpace(strings.TrimPrefix(constraint, op)))
+			switch op {
+			case ">=":
+				satisfied = c >= 0
+			case "<=":
+				satisfied = c <= 0
+			case ">":
+				satisfied = c > 0
+			case "<":
+				satisfied = c < 0
+			case "=":
+				satisfied = c == 0
+			}
+			break
+		}
+		if !satisfied {
+			return false
+		}
+	}
+	return true
+}
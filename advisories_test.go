@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/aidenwang9867/depdiffvis/osv"
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	"github.com/google/go-github/v38/github"
+)
+
+// advisoryResponse is a trimmed response of the global security advisories API.
+const advisoryResponse = `{
+  "ghsa_id": "GHSA-p6mc-m468-83gw",
+  "cve_id": "CVE-2020-8203",
+  "severity": "high",
+  "vulnerabilities": [
+    {
+      "package": {"ecosystem": "npm", "name": "lodash"},
+      "vulnerable_version_range": ">= 3.7.0, < 4.17.19",
+      "first_patched_version": "4.17.19",
+      "vulnerable_functions": []
+    },
+    {
+      "package": {"ecosystem": "npm", "name": "lodash"},
+      "vulnerable_version_range": "< 3.7.0",
+      "first_patched_version": null,
+      "vulnerable_functions": []
+    },
+    {
+      "package": {"ecosystem": "npm", "name": "lodash-es"},
+      "vulnerable_version_range": "< 4.17.20",
+      "first_patched_version": "4.17.20",
+      "vulnerable_functions": []
+    }
+  ]
+}`
+
+// fakeAdvisories is a fake of the global security advisories API, serving a single advisory.
+type fakeAdvisories struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (f *fakeAdvisories) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	if r.Method != http.MethodGet || r.URL.Path != "/advisories/GHSA-p6mc-m468-83gw" {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`)) //nolint:errcheck
+		return
+	}
+	w.Write([]byte(advisoryResponse)) //nolint:errcheck
+}
+
+func TestAdvisoryFixes_FixedVersion(t *testing.T) {
+	t.Parallel()
+	fake := &fakeAdvisories{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client := github.NewClient(server.Client())
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+	client.BaseURL = baseURL
+	fixes := newAdvisoryFixes(context.Background(), client)
+
+	//nolint
+	tests := []struct {
+		name      string
+		id        string
+		ecosystem string
+		pkg       string
+		version   string
+		want      string
+	}{
+		{name: "affected", id: "GHSA-p6mc-m468-83gw", ecosystem: "npm", pkg: "lodash", version: "4.17.15", want: "4.17.19"},
+		{name: "lower bound", id: "GHSA-p6mc-m468-83gw", ecosystem: "npm", pkg: "lodash", version: "3.7.0", want: "4.17.19"},
+		{name: "patched", id: "GHSA-p6mc-m468-83gw", ecosystem: "npm", pkg: "lodash", version: "4.17.19", want: ""},
+		{name: "no patch", id: "GHSA-p6mc-m468-83gw", ecosystem: "npm", pkg: "lodash", version: "3.0.0", want: ""},
+		{
+			name: "other package", id: "GHSA-p6mc-m468-83gw", ecosystem: "npm", pkg: "lodash-es", version: "4.17.15",
+			want: "4.17.20",
+		},
+		{name: "other ecosystem", id: "GHSA-p6mc-m468-83gw", ecosystem: "PyPI", pkg: "lodash", version: "4.17.15", want: ""},
+		{
+			name: "withdrawn advisory", id: "GHSA-xxxx-xxxx-xxxx", ecosystem: "npm", pkg: "lodash", version: "4.17.15",
+			want: "",
+		},
+		{name: "not an advisory", id: "PYSEC-2021-1", ecosystem: "npm", pkg: "lodash", version: "4.17.15", want: ""},
+	}
+	// Cannot run parallel tests because the advisories are cached by the fix source.
+	for _, tt := range tests {
+		got, err := fixes.FixedVersion(tt.id, tt.ecosystem, tt.pkg, tt.version)
+		if err != nil {
+			t.Fatalf("%s: FixedVersion: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: FixedVersion() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	// The withdrawn advisory is not fetched again, and neither is the existing one.
+	if fake.requests != 2 {
+		t.Errorf("got %d requests, want 2", fake.requests)
+	}
+
+	added, npm, version := pkg.Added, "npm", "4.17.15"
+	results := []pkg.DependencyCheckResult{{
+		Name: "lodash", ChangeType: &added, Ecosystem: &npm, Version: &version,
+		Vulnerabilities: []pkg.Vulnerability{{ID: "GHSA-p6mc-m468-83gw"}},
+	}}
+	if err := osv.SuggestFixes(results, fixes); err != nil {
+		t.Fatalf("SuggestFixes: %v", err)
+	}
+	if s := results[0].FixSuggestion; s == nil || s.Version != "4.17.19" || !s.SameMajor {
+		t.Errorf("got fix suggestion %+v, want 4.17.19 in the same major", s)
+	}
+}
+
+func TestInVersionRange(t *testing.T) {
+	t.Parallel()
+	//nolint
+	tests := []struct {
+		ecosystem    string
+		version      string
+		versionRange string
+		want         bool
+	}{
+		{ecosystem: "npm", version: "1.2.2", versionRange: "< 1.2.3", want: true},
+		{ecosystem: "npm", version: "1.2.3", versionRange: "< 1.2.3", want: false},
+		{ecosystem: "npm", version: "1.2.3", versionRange: "<= 1.2.3", want: true},
+		{ecosystem: "npm", version: "0.9.0", versionRange: ">= 1.0.0, < 1.2.3", want: false},
+		{ecosystem: "npm", version: "1.0.0", versionRange: ">= 1.0.0, < 1.2.3", want: true},
+		{ecosystem: "npm", version: "1.0.0", versionRange: "> 1.0.0", want: false},
+		{ecosystem: "npm", version: "1.0.0", versionRange: "= 1.0.0", want: true},
+		{ecosystem: "Go", version: "v1.0.0", versionRange: "< 1.0.1", want: true},
+		{ecosystem: "PyPI", version: "2.0rc1", versionRange: "< 2.0", want: true},
+		{ecosystem: "npm", version: "1.0.0", versionRange: "", want: false},
+		{ecosystem: "npm", version: "1.0.0", versionRange: "~1.0.0", want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.ecosystem+" "+tt.version+" "+tt.versionRange, func(t *testing.T) {
+			t.Parallel()
+			if got := inVersionRange(tt.ecosystem, tt.version, tt.versionRange); got != tt.want {
+				t.Errorf("inVersionRange(%q, %q) = %v, want %v", tt.version, tt.versionRange, got, tt.want)
+			}
+		})
+	}
+}
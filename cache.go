@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	"github.com/ossf/scorecard/v4/checker"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sclog "github.com/ossf/scorecard/v4/log"
+	scpkg "github.com/ossf/scorecard/v4/pkg"
+)
+
+// scorecardCacheTTL is how long the cached check results of a repository are used. The checks run at the HEAD
+// of the source repositories of dependencies, so that their results change over time.
+const scorecardCacheTTL = 24 * time.Hour
+
+// scorecardCache caches the check results of the source repositories of dependencies in a directory, so that
+// the checks don't run again on the same repositories, e.g. on every push to a pull request. Results are
+// cached as the JSON results of dependency-diff, one file per repository and set of checks. A nil cache
+// caches nothing.
+type scorecardCache struct {
+	dir       string
+	checkDocs docs.Doc
+	now       func() time.Time
+}
+
+func newScorecardCache(dir string) (*scorecardCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating the cache directory: %w", err)
+	}
+	checkDocs, err := docs.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the check docs: %w", err)
+	}
+	return &scorecardCache{dir: dir, checkDocs: checkDocs, now: time.Now}, nil
+}
+
+// path returns the cache file of the results of the checks on a repository.
+func (c *scorecardCache) path(source string, checksToRun checker.CheckNameToFnMap) string {
+	names := []string{}
+	for name := range checksToRun {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	key := sha256.Sum256([]byte(source + "\n" + strings.Join(names, ",")))
+	return filepath.Join(c.dir, hex.EncodeToString(key[:])+".json")
+}
+
+// get returns the cached results of the checks on a repository, or nil if they are not cached or expired.
+// Cache files which cannot be read are ignored, so that the checks run again.
+func (c *scorecardCache) get(source string, checksToRun checker.CheckNameToFnMap) *scpkg.ScorecardResult {
+	if c == nil {
+		return nil
+	}
+	f, err := os.Open(c.path(source, checksToRun))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || c.now().Sub(info.ModTime()) > scorecardCacheTTL {
+		return nil
+	}
+	results, err := pkg.DependencydiffResultsFromJSON(f)
+	if err != nil || len(results) != 1 {
+		return nil
+	}
+	return results[0].ScorecardResultWithError.ScorecardResult
+}
+
+// put caches the results of the checks on a repository. The cache file is replaced atomically, so that
+// concurrent runs never read a partial file.
+func (c *scorecardCache) put(source string, checksToRun checker.CheckNameToFnMap, result *scpkg.ScorecardResult) error {
+	if c == nil {
+		return nil
+	}
+	f, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return fmt.Errorf("error caching the results of %s: %w", source, err)
+	}
+	defer os.Remove(f.Name())
+	results := []pkg.DependencyCheckResult{{
+		SourceRepository:         &source,
+		Name:                     source,
+		ScorecardResultWithError: pkg.ScorecardResultWithError{ScorecardResult: result},
+	}}
+	// The details are cached at the debug level, so that they can be rendered at any level.
+	err = pkg.DependencydiffResultsAsJSON(results, sclog.DebugLevel, c.checkDocs, nil, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path(source, checksToRun))
+	}
+	if err != nil {
+		return fmt.Errorf("error caching the results of %s: %w", source, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	"github.com/ossf/scorecard/v4/checker"
+	"github.com/ossf/scorecard/v4/checks"
+	scpkg "github.com/ossf/scorecard/v4/pkg"
+)
+
+func TestScorecardCache(t *testing.T) {
+	t.Parallel()
+	cache, err := newScorecardCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("newScorecardCache: %v", err)
+	}
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	source := "github.com/owner/repo"
+	license := checker.CheckNameToFnMap{checks.CheckLicense: checker.Check{}}
+	codeReview := checker.CheckNameToFnMap{checks.CheckCodeReview: checker.Check{}}
+	result := &scpkg.ScorecardResult{
+		Repo: scpkg.RepoInfo{Name: source, CommitSHA: "abc"},
+		Checks: []checker.CheckResult{{
+			Name: checks.CheckLicense, Score: 9, Reason: "license file detected",
+			Details: []checker.CheckDetail{{Type: checker.DetailInfo, Msg: checker.LogMessage{Text: "LICENSE"}}},
+		}},
+	}
+	if got := cache.get(source, license); got != nil {
+		t.Fatalf("got %+v from an empty cache", got)
+	}
+	if err := cache.put(source, license, result); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	got := cache.get(source, license)
+	if got == nil || got.Repo != result.Repo || len(got.Checks) != 1 {
+		t.Fatalf("got %+v, want %+v", got, result)
+	}
+	if c := got.Checks[0]; c.Name != checks.CheckLicense || c.Score != 9 || c.Reason != "license file detected" ||
+		len(c.Details) != 1 || c.Details[0].Msg.Text != "LICENSE" {
+		t.Errorf("got check %+v, want %+v", c, result.Checks[0])
+	}
+	// The results of other checks or repositories are cached separately.
+	if got := cache.get(source, codeReview); got != nil {
+		t.Errorf("got %+v for other checks", got)
+	}
+	if got := cache.get("github.com/owner/other", license); got != nil {
+		t.Errorf("got %+v for another repository", got)
+	}
+	now = now.Add(scorecardCacheTTL + time.Minute)
+	if got := cache.get(source, license); got != nil {
+		t.Errorf("got expired result %+v", got)
+	}
+	// A nil cache caches nothing.
+	var disabled *scorecardCache
+	if err := disabled.put(source, license, result); err != nil || disabled.get(source, license) != nil {
+		t.Errorf("a nil cache cached the result")
+	}
+}
+
+func TestGetScorecardCheckResult_Cached(t *testing.T) {
+	t.Parallel()
+	cache, err := newScorecardCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("newScorecardCache: %v", err)
+	}
+	runs := 0
+	dCtx := &dependencydiffContext{
+		cache: cache,
+		runScorecard: func(
+			dCtx *dependencydiffContext, dSrcRepo string, checksToRun checker.CheckNameToFnMap,
+		) (scpkg.ScorecardResult, error) {
+			runs++
+			return scpkg.ScorecardResult{
+				Repo:   scpkg.RepoInfo{Name: dSrcRepo},
+				Checks: []checker.CheckResult{{Name: checks.CheckLicense, Score: 10}},
+			}, nil
+		},
+	}
+	source := "github.com/owner/repo"
+	license := checker.CheckNameToFnMap{checks.CheckLicense: checker.Check{}}
+	for i := 0; i < 2; i++ {
+		r := pkg.DependencyCheckResult{Name: "dep", SourceRepository: &source}
+		getScorecardCheckResult(dCtx, license, &r)
+		if r.Status != pkg.StatusEvaluated || r.ScorecardResultWithError.ScorecardResult.Repo.Name != source {
+			t.Errorf("run %d: got status %s with %+v", i, r.Status, r.ScorecardResultWithError)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("got %d runs, want the second result from the cache", runs)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	"github.com/google/go-github/v38/github"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sclog "github.com/ossf/scorecard/v4/log"
+)
+
+const (
+	// maxAnnotationsPerRequest is the maximum number of annotations the Checks API accepts in a request.
+	maxAnnotationsPerRequest = 50
+
+	// maxCheckRunSummaryBytes is the maximum size of the summary of a check run.
+	maxCheckRunSummaryBytes = 65535
+)
+
+// checkRunConclusions are the conclusions of the check run following the policy verdicts.
+var checkRunConclusions = map[pkg.Verdict]string{
+	pkg.VerdictPass: "success",
+	pkg.VerdictWarn: "neutral",
+	pkg.VerdictFail: "failure",
+}
+
+// publishCheckRunResults publishes the policy evaluation as a check run of the HEAD commit, which must be
+// given as a SHA, with the markdown report as its summary.
+func publishCheckRunResults(
+	ctx context.Context, repoURI, headSHA string, logLevel sclog.Level,
+	results []pkg.DependencyCheckResult, checkDocs docs.Doc, policy *pkg.DependencydiffPolicy,
+	evaluation *pkg.PolicyEvaluation,
+) error {
+	ownerAndRepo := strings.Split(repoURI, "/")
+	if len(ownerAndRepo) != 2 {
+		return fmt.Errorf("%w: repo uri input", errInvalid)
+	}
+	markdown, err := SprintDependencyChecksToMarkdown(results, logLevel, checkDocs, policy, markdownLimit{
+		maxBytes: maxCheckRunSummaryBytes,
+	})
+	if err != nil {
+		return fmt.Errorf("error formatting the results as markdown: %w", err)
+	}
+	return publishCheckRun(
+		ctx, newGitHubClient(ctx, logLevel), ownerAndRepo[0], ownerAndRepo[1], headSHA, evaluation,
+		orNoChanges(*markdown),
+	)
+}
+
+// publishCheckRun creates a completed check run concluding with the policy verdict, annotating the line of
+// each dependency violating the policy in its manifest. Annotations exceeding the limit of a request are
+// added by updating the check run.
+func publishCheckRun(
+	ctx context.Context, client *github.Client, owner, repo, headSHA string,
+	evaluation *pkg.PolicyEvaluation, summary string,
+) error {
+	annotations := violationAnnotations(evaluation.Violations)
+	title := "No policy violations"
+	if len(evaluation.Violations) > 0 {
+		title = fmt.Sprintf("%d policy violations", len(evaluation.Violations))
+	}
+	batch := annotations
+	if len(batch) > maxAnnotationsPerRequest {
+		batch = batch[:maxAnnotationsPerRequest]
+	}
+	run, _, err := client.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+		Name:        Depdiff,
+		HeadSHA:     headSHA,
+		Status:      github.String("completed"),
+		Conclusion:  github.String(checkRunConclusions[evaluation.Verdict]),
+		CompletedAt: &github.Timestamp{Time: time.Now()},
+		Output: &github.CheckRunOutput{
+			Title:       &title,
+			Summary:     &summary,
+			Annotations: batch,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating the check run: %w", err)
+	}
+	for start := maxAnnotationsPerRequest; start < len(annotations); start += maxAnnotationsPerRequest {
+		end := start + maxAnnotationsPerRequest
+		if end > len(annotations) {
+			end = len(annotations)
+		}
+		_, _, err := client.Checks.UpdateCheckRun(ctx, owner, repo, run.GetID(), github.UpdateCheckRunOptions{
+			Name: Depdiff,
+			Output: &github.CheckRunOutput{
+				Title:       &title,
+				Summary:     &summary,
+				Annotations: annotations[start:end],
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("error adding annotations to the check run: %w", err)
+		}
+	}
+	return nil
+}
+
+// violationAnnotations annotates the line declaring each dependency violating the policy in its manifest, or
+// the first line if it is not found. Dependencies without a manifest cannot be annotated.
+func violationAnnotations(violations []pkg.PolicyViolation) []*github.CheckRunAnnotation {
+	annotations := []*github.CheckRunAnnotation{}
+	for _, v := range violations {
+		d := v.Dependency
+		if d.ManifestPath == nil || *d.ManifestPath == "" {
+			continue
+		}
+		line := int(pkg.FindDependencyLine(d))
+		if line == 0 {
+			line = 1
+		}
+		level := "warning"
+		if v.Verdict == pkg.VerdictFail {
+			level = "failure"
+		}
+		annotations = append(annotations, &github.CheckRunAnnotation{
+			Path:            github.String(*d.ManifestPath),
+			StartLine:       github.Int(line),
+			EndLine:         github.Int(line),
+			AnnotationLevel: github.String(level),
+			Title:           github.String(fmt.Sprintf("%s @ %s", d.Name, versionOf(d))),
+			Message:         github.String(fmt.Sprintf("%s @ %s %s", d.Name, versionOf(d), v.Message)),
+		})
+	}
+	return annotations
+}
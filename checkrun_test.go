@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	"github.com/google/go-github/v38/github"
+)
+
+// fakeChecks is a fake of the GitHub Checks API recording the check run requests.
+type fakeChecks struct {
+	mu      sync.Mutex
+	created []github.CreateCheckRunOptions
+	updated []github.UpdateCheckRunOptions
+}
+
+func (f *fakeChecks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/repo/check-runs":
+		var opts github.CreateCheckRunOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.created = append(f.created, opts)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(github.CheckRun{ID: github.Int64(7)}) //nolint:errcheck
+	case r.Method == http.MethodPatch && r.URL.Path == "/repos/owner/repo/check-runs/7":
+		var opts github.UpdateCheckRunOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.updated = append(f.updated, opts)
+		json.NewEncoder(w).Encode(github.CheckRun{ID: github.Int64(7)}) //nolint:errcheck
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestPublishCheckRun(t *testing.T) {
+	t.Parallel()
+	manifest := filepath.Join(t.TempDir(), "go.mod")
+	content := "module example.com/m\n\nrequire (\n"
+	evaluation := &pkg.PolicyEvaluation{Verdict: pkg.VerdictFail}
+	for i := 0; i < 60; i++ {
+		name, version := fmt.Sprintf("example.com/dep%d", i), "v1.0.0"
+		content += fmt.Sprintf("\t%s %s\n", name, version)
+		verdict := pkg.VerdictWarn
+		if i == 0 {
+			verdict = pkg.VerdictFail
+		}
+		evaluation.Violations = append(evaluation.Violations, pkg.PolicyViolation{
+			Dependency: &pkg.DependencyCheckResult{Name: name, Version: &version, ManifestPath: &manifest},
+			Rule:       pkg.RuleVulnerability,
+			Message:    "introduces a vulnerability",
+			Verdict:    verdict,
+		})
+	}
+	if err := os.WriteFile(manifest, []byte(content+")\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	// A dependency without a manifest is not annotated.
+	evaluation.Violations = append(evaluation.Violations, pkg.PolicyViolation{
+		Dependency: &pkg.DependencyCheckResult{Name: "unknown"},
+		Verdict:    pkg.VerdictWarn,
+	})
+
+	fake := &fakeChecks{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client := github.NewClient(server.Client())
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+	client.BaseURL = baseURL
+
+	if err := publishCheckRun(context.Background(), client, "owner", "repo", "abc", evaluation, "report"); err != nil {
+		t.Fatalf("publishCheckRun: %v", err)
+	}
+	if len(fake.created) != 1 || len(fake.updated) != 1 {
+		t.Fatalf("got %d created and %d updated check runs, want 1 and 1", len(fake.created), len(fake.updated))
+	}
+	created := fake.created[0]
+	if created.Name != Depdiff || created.HeadSHA != "abc" || created.GetConclusion() != "failure" {
+		t.Errorf("got check run %s on %s concluding %s, want %s on abc concluding failure",
+			created.Name, created.HeadSHA, created.GetConclusion(), Depdiff)
+	}
+	if created.Output.GetSummary() != "report" || !strings.HasPrefix(created.Output.GetTitle(), "61 ") {
+		t.Errorf("got output %s: %s", created.Output.GetTitle(), created.Output.GetSummary())
+	}
+	annotations := append(created.Output.Annotations, fake.updated[0].Output.Annotations...)
+	if len(created.Output.Annotations) != maxAnnotationsPerRequest || len(annotations) != 60 {
+		t.Fatalf("got %d annotations in the first request and %d in total, want %d and 60",
+			len(created.Output.Annotations), len(annotations), maxAnnotationsPerRequest)
+	}
+	for i, a := range annotations {
+		// The dependencies are declared from line 4 on.
+		if a.GetPath() != manifest || a.GetStartLine() != i+4 || a.GetEndLine() != i+4 {
+			t.Errorf("annotation %d: got %s:%d-%d, want %s:%d", i, a.GetPath(), a.GetStartLine(), a.GetEndLine(),
+				manifest, i+4)
+		}
+		wantLevel := "warning"
+		if i == 0 {
+			wantLevel = "failure"
+		}
+		if a.GetAnnotationLevel() != wantLevel {
+			t.Errorf("annotation %d: got level %s, want %s", i, a.GetAnnotationLevel(), wantLevel)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	"github.com/google/go-github/v38/github"
+	"github.com/ossf/scorecard/v4/clients/githubrepo/roundtripper"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sclog "github.com/ossf/scorecard/v4/log"
+)
+
+// commentMarker tags the dependency-diff comment of a pull request, so that later runs update it instead of
+// posting a new one.
+const commentMarker = "<!-- dependency-diff -->"
+
+// publishResults publishes the markdown report as the dependency-diff comment of the pull request.
+func publishResults(
+	ctx context.Context, repoURI, pullRequest string, logLevel sclog.Level,
+	results []pkg.DependencyCheckResult, checkDocs docs.Doc, policy *pkg.DependencydiffPolicy,
+	fullReportFile string,
+) error {
+	ownerAndRepo := strings.Split(repoURI, "/")
+	if len(ownerAndRepo) != 2 {
+		return fmt.Errorf("%w: repo uri input", errInvalid)
+	}
+	number, err := strconv.Atoi(pullRequest)
+	if err != nil {
+		return fmt.Errorf("%w: pull request number %s", errInvalid, pullRequest)
+	}
+	markdown, err := SprintDependencyChecksToMarkdown(results, logLevel, checkDocs, policy, markdownLimit{
+		maxBytes:       maxCommentBytes - len(commentMarker) - 1,
+		fullReportFile: fullReportFile,
+	})
+	if err != nil {
+		return fmt.Errorf("error formatting the results as markdown: %w", err)
+	}
+	return publishComment(
+		ctx, newGitHubClient(ctx, logLevel), ownerAndRepo[0], ownerAndRepo[1], number, *markdown, len(results) > 0,
+	)
+}
+
+// newGitHubClient creates a GitHub client authenticated with the access token of the environment.
+func newGitHubClient(ctx context.Context, logLevel sclog.Level) *github.Client {
+	ghrt := roundtripper.NewTransport(ctx, sclog.NewLogger(logLevel))
+	return github.NewClient(&http.Client{Transport: ghrt})
+}
+
+// publishComment creates or updates the dependency-diff comment of a pull request with the markdown report.
+// The comment is deleted if there are no dependency changes left, e.g. after they were reverted.
+func publishComment(
+	ctx context.Context, client *github.Client, owner, repo string, number int, markdown string, hasChanges bool,
+) error {
+	existing, err := findComment(ctx, client, owner, repo, number)
+	if err != nil {
+		return err
+	}
+	if !hasChanges {
+		if existing == nil {
+			return nil
+		}
+		if _, err := client.Issues.DeleteComment(ctx, owner, repo, existing.GetID()); err != nil {
+			return fmt.Errorf("error deleting the comment: %w", err)
+		}
+		return nil
+	}
+	body := commentMarker + "\n" + markdown
+	if existing == nil {
+		if _, _, err := client.Issues.CreateComment(ctx, owner, repo, number, &github.IssueComment{Body: &body}); err != nil {
+			return fmt.Errorf("error creating the comment: %w", err)
+		}
+		return nil
+	}
+	if existing.GetBody() == body {
+		return nil
+	}
+	if _, _, err := client.Issues.EditComment(ctx, owner, repo, existing.GetID(), &github.IssueComment{Body: &body}); err != nil {
+		return fmt.Errorf("error updating the comment: %w", err)
+	}
+	return nil
+}
+
+// findComment finds the dependency-diff comment of a pull request by its marker, which is nil if not found.
+func findComment(
+	ctx context.Context, client *github.Client, owner, repo string, number int,
+) (*github.IssueComment, error) {
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := client.Issues.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error listing the comments: %w", err)
+		}
+		for _, c := range comments {
+			if strings.HasPrefix(c.GetBody(), commentMarker) {
+				return c, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
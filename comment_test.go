@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v38/github"
+)
+
+// fakeComments is a fake of the GitHub issue comments API of a single pull request.
+type fakeComments struct {
+	mu       sync.Mutex
+	comments []*github.IssueComment
+	nextID   int64
+}
+
+func (f *fakeComments) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/issues/1/comments":
+		// Serve a comment per page to exercise the pagination.
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil {
+			page = 1
+		}
+		if page < len(f.comments) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, page+1))
+		}
+		comments := []*github.IssueComment{}
+		if page <= len(f.comments) {
+			comments = f.comments[page-1 : page]
+		}
+		json.NewEncoder(w).Encode(comments) //nolint:errcheck
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/repo/issues/1/comments":
+		var c github.IssueComment
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.nextID++
+		c.ID = &f.nextID
+		f.comments = append(f.comments, &c)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(c) //nolint:errcheck
+	case strings.HasPrefix(r.URL.Path, "/repos/owner/repo/issues/comments/"):
+		id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/issues/comments/"), 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for i, c := range f.comments {
+			if c.GetID() != id {
+				continue
+			}
+			switch r.Method {
+			case http.MethodPatch:
+				if err := json.NewDecoder(r.Body).Decode(c); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				json.NewEncoder(w).Encode(c) //nolint:errcheck
+			case http.MethodDelete:
+				f.comments = append(f.comments[:i], f.comments[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeComments) bodies() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	bodies := []string{}
+	for _, c := range f.comments {
+		bodies = append(bodies, c.GetBody())
+	}
+	return bodies
+}
+
+func TestPublishComment(t *testing.T) {
+	t.Parallel()
+	other := "LGTM"
+	fake := &fakeComments{comments: []*github.IssueComment{{ID: github.Int64(100), Body: &other}}, nextID: 100}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client := github.NewClient(server.Client())
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+	client.BaseURL = baseURL
+
+	//nolint
+	steps := []struct {
+		name       string
+		markdown   string
+		hasChanges bool
+		want       []string
+	}{
+		{
+			name:       "no changes and no comment",
+			hasChanges: false,
+			want:       []string{other},
+		},
+		{
+			name:       "create",
+			markdown:   "first report",
+			hasChanges: true,
+			want:       []string{other, commentMarker + "\nfirst report"},
+		},
+		{
+			name:       "update",
+			markdown:   "second report",
+			hasChanges: true,
+			want:       []string{other, commentMarker + "\nsecond report"},
+		},
+		{
+			name:       "delete",
+			hasChanges: false,
+			want:       []string{other},
+		},
+	}
+	// The steps run in order against the same pull request.
+	for _, step := range steps {
+		err := publishComment(context.Background(), client, "owner", "repo", 1, step.markdown, step.hasChanges)
+		if err != nil {
+			t.Fatalf("%s: publishComment: %v", step.name, err)
+		}
+		got := fake.bodies()
+		if strings.Join(got, "|") != strings.Join(step.want, "|") {
+			t.Errorf("%s: got comments %q, want %q", step.name, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/aidenwang9867/depdiffvis/options"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// newConfigCommand creates the config command, which shows the configuration of dependency-diff.
+func newConfigCommand(opts *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show the configuration of dependency-diff",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "print",
+		Short: "Print the effective configuration, resolved from the flags, environment variables and config file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configFile, err := resolveOptions(cmd, opts)
+			if err != nil {
+				return err
+			}
+			return printConfig(cmd.OutOrStdout(), configFile, opts)
+		},
+	})
+	return cmd
+}
+
+// resolveOptions resolves the options which are not given by flags nor environment variables from the inputs
+// of the action in GitHub Actions, or else from the config file. It returns the path of the config file, which
+// is empty if there is none.
+func resolveOptions(cmd *cobra.Command, opts *options.Options) (string, error) {
+	configFile := opts.ConfigFile
+	if configFile == "" {
+		found, err := options.FindConfigFile(".")
+		if err != nil {
+			return "", fmt.Errorf("error finding the config file: %w", err)
+		}
+		configFile = found
+	}
+	if configFile != "" {
+		config, err := options.ReadConfig(configFile)
+		if err != nil {
+			return "", fmt.Errorf("error reading the config file: %w", err)
+		}
+		opts.ApplyConfig(config, cmd.Flags().Changed)
+	}
+	if isGitHubActions() {
+		applyActionInputs(opts, cmd.Flags().Changed)
+	}
+	if err := opts.SetDepdiffCommits(); err != nil {
+		return "", err
+	}
+	return configFile, nil
+}
+
+// printConfig prints the effective configuration of the options in the format of the config file.
+func printConfig(w io.Writer, configFile string, opts *options.Options) error {
+	if configFile == "" {
+		configFile = "none"
+	}
+	if _, err := fmt.Fprintf(w, "# Config file: %s\n", configFile); err != nil {
+		return fmt.Errorf("error printing the config: %w", err)
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(opts.DepdiffConfig()); err != nil {
+		return fmt.Errorf("error printing the config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("error printing the config: %w", err)
+	}
+	return nil
+}
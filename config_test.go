@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aidenwang9867/depdiffvis/options"
+	"gopkg.in/yaml.v3"
+)
+
+const testConfig = `repo: config/repo
+base: config-base
+head: config-head
+checks: [Code-Review]
+change_types: [removed]
+dry_run: true
+`
+
+// printedConfig runs the config print command with the arguments, and decodes the printed config.
+func printedConfig(t *testing.T, args ...string) (string, *options.Config) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := newDepdiffCommand(options.New())
+	cmd.SetArgs(append([]string{"config", "print"}, args...))
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("config print: %v", err)
+	}
+	header, _, _ := strings.Cut(out.String(), "\n")
+	config := &options.Config{}
+	if err := yaml.Unmarshal(out.Bytes(), config); err != nil {
+		t.Fatalf("yaml.Unmarshal: %v", err)
+	}
+	return header, config
+}
+
+//nolint:paralleltest
+func TestResolveOptions_ActionInputs(t *testing.T) {
+	// Cannot run parallel tests because of the ENV variables.
+	configFile := filepath.Join(t.TempDir(), options.ConfigFileName)
+	if err := os.WriteFile(configFile, []byte(testConfig), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	t.Setenv(envVarGitHubActions, "true")
+	t.Setenv(inputOwnerRepo, "input/repo")
+	t.Setenv(inputBase, "input-base")
+	t.Setenv(inputHead, "input-head")
+	// The default inputs of the action are empty lists of checks, which leave the config as it is.
+	t.Setenv(inputChecksToRun, "[]")
+	t.Setenv(inputChangeTypesToRun, `["added", "updated"]`)
+	t.Setenv(inputDryRun, "false")
+	t.Setenv(options.EnvVarDepdiffHead, "env-head")
+
+	header, got := printedConfig(t, "--config", configFile, "--repo", "flag/repo")
+	if want := "# Config file: " + configFile; header != want {
+		t.Errorf("got header %q, want %q", header, want)
+	}
+	want := &options.Config{
+		// Flags take precedence over the environment variables, which take precedence over the inputs,
+		// which take precedence over the config.
+		Repo:        "flag/repo",
+		Head:        "env-head",
+		Base:        "input-base",
+		ChangeTypes: []string{"added", "updated"},
+		DryRun:      false,
+		Checks:      []string{"Code-Review"},
+		Format:      options.FormatDefault,
+		Workers:     options.DefaultWorkers,
+		LogLevel:    options.DefaultLogLevel,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got config %+v, want %+v", got, want)
+	}
+
+	// Outside of GitHub Actions, the inputs are ignored.
+	t.Setenv(envVarGitHubActions, "")
+	_, got = printedConfig(t, "--config", configFile)
+	want = &options.Config{
+		Repo:        "config/repo",
+		Base:        "config-base",
+		Head:        "env-head",
+		Checks:      []string{"Code-Review"},
+		ChangeTypes: []string{"removed"},
+		DryRun:      true,
+		Format:      options.FormatDefault,
+		Workers:     options.DefaultWorkers,
+		LogLevel:    options.DefaultLogLevel,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got config %+v, want %+v", got, want)
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	t.Parallel()
+	opts := &options.Options{
+		Repo:              "owner/repo",
+		Base:              "main",
+		Head:              "dev",
+		ChecksToRun:       []string{"License"},
+		DepdiffPolicyFile: "policy.yml",
+		Weights:           map[string]float64{"License": 2},
+		Workers:           4,
+	}
+	//nolint
+	tests := []struct {
+		name       string
+		configFile string
+		wantHeader string
+	}{
+		{name: "config file", configFile: "/repo/.depdiff.yml", wantHeader: "# Config file: /repo/.depdiff.yml"},
+		{name: "no config file", configFile: "", wantHeader: "# Config file: none"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			if err := printConfig(&out, tt.configFile, opts); err != nil {
+				t.Fatalf("printConfig: %v", err)
+			}
+			header, body, _ := strings.Cut(out.String(), "\n")
+			if header != tt.wantHeader {
+				t.Errorf("got header %q, want %q", header, tt.wantHeader)
+			}
+			// The printed config is a valid config file of the same options.
+			config := &options.Config{}
+			decoder := yaml.NewDecoder(strings.NewReader(body))
+			decoder.KnownFields(true)
+			if err := decoder.Decode(config); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if !reflect.DeepEqual(config, opts.DepdiffConfig()) {
+				t.Errorf("got config %+v, want %+v", config, opts.DepdiffConfig())
+			}
+			// Unset options are omitted.
+			if strings.Contains(body, "cache_dir") || strings.Contains(body, "template") {
+				t.Errorf("got unset options in %q", body)
+			}
+		})
+	}
+}
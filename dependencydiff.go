@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/aidenwang9867/depdiffvis/pkg"
 	"github.com/ossf/scorecard/v4/checker"
@@ -22,17 +23,30 @@ type dependencydiffContext struct {
 	logger                          *sclog.Logger
 	ownerName, repoName, base, head string
 	ctx                             context.Context
-	ghRepo                          clients.Repo
-	ghRepoClient                    clients.RepoClient
-	ossFuzzClient                   clients.RepoClient
-	vulnsClient                     clients.VulnerabilitiesClient
-	ciiClient                       clients.CIIBestPracticesClient
 	changeTypesToCheck              map[pkg.ChangeType]bool
 	checkNamesToRun                 []string
+	dryRun                          bool
+	workers                         int
+	cache                           *scorecardCache
+	runScorecard                    scorecardRunner
 	dependencydiffs                 []dependency
 	results                         []pkg.DependencyCheckResult
 }
 
+// scorecardRunner runs the checks on the source repository of a dependency. It is runScorecard but in tests.
+type scorecardRunner func(
+	dCtx *dependencydiffContext, dSrcRepo string, checksToRun checker.CheckNameToFnMap,
+) (scpkg.ScorecardResult, error)
+
+// scorecardClients are the repo and clients to run the checks on the source repository of a dependency.
+type scorecardClients struct {
+	repo          clients.Repo
+	repoClient    clients.RepoClient
+	ossFuzzClient clients.RepoClient
+	vulnsClient   clients.VulnerabilitiesClient
+	ciiClient     clients.CIIBestPracticesClient
+}
+
 // GetDependencyDiffResults gets dependency changes between two given code commits BASE and HEAD
 // along with the Scorecard check results of the dependencies, and returns a slice of DependencyCheckResult.
 // TO use this API, an access token must be set. See https://github.com/ossf/scorecard#authentication.
@@ -42,6 +56,33 @@ func GetDependencyDiffResults(
 	base, head string, /* Two code commits base and head, can use either SHAs or branch names. */
 	checksToRun []string, /* A list of enabled check names to run. */
 	changeTypesToCheck map[pkg.ChangeType]bool, /* A list of change types for which to surface scorecard results. */
+	workers int, /* The number of dependencies to run the checks on concurrently, at least 1. */
+	cacheDir string, /* The directory to cache the check results in, or "" not to cache them. */
+) ([]pkg.DependencyCheckResult, error) {
+	return getDependencyDiffResults(ctx, repoURI, base, head, checksToRun, changeTypesToCheck, workers, cacheDir, false)
+}
+
+// GetDependencyDiffs gets dependency changes between two given code commits BASE and HEAD without running
+// the Scorecard checks on the dependencies, which is a dry run of GetDependencyDiffResults. The dependencies
+// the checks would run on have the planned status, while the others have the status of why they are skipped.
+func GetDependencyDiffs(
+	ctx context.Context,
+	repoURI string, /* Use the format "ownerName/repoName" as the repo URI, such as "ossf/scorecard". */
+	base, head string, /* Two code commits base and head, can use either SHAs or branch names. */
+	changeTypesToCheck map[pkg.ChangeType]bool, /* A list of change types for which to plan scorecard results. */
+) ([]pkg.DependencyCheckResult, error) {
+	return getDependencyDiffResults(ctx, repoURI, base, head, nil, changeTypesToCheck, 1, "", true)
+}
+
+func getDependencyDiffResults(
+	ctx context.Context,
+	repoURI string,
+	base, head string,
+	checksToRun []string,
+	changeTypesToCheck map[pkg.ChangeType]bool,
+	workers int,
+	cacheDir string,
+	dryRun bool,
 ) ([]pkg.DependencyCheckResult, error) {
 
 	logger := sclog.NewLogger(sclog.DefaultLevel)
@@ -59,6 +100,16 @@ func GetDependencyDiffResults(
 		ctx:                ctx,
 		changeTypesToCheck: changeTypesToCheck,
 		checkNamesToRun:    checksToRun,
+		dryRun:             dryRun,
+		workers:            workers,
+		runScorecard:       runScorecard,
+	}
+	if cacheDir != "" {
+		cache, err := newScorecardCache(cacheDir)
+		if err != nil {
+			return nil, err
+		}
+		dCtx.cache = cache
 	}
 	// Fetch the raw dependency diffs. This API will also handle error cases such as invalid base or head.
 	err := fetchRawDependencyDiffData(&dCtx)
@@ -93,31 +144,57 @@ func mapDependencyEcosystemNaming(deps []dependency) error {
 	return nil
 }
 
-func initRepoAndClientByChecks(dCtx *dependencydiffContext, dSrcRepo string) error {
+func initRepoAndClientByChecks(dCtx *dependencydiffContext, dSrcRepo string) (*scorecardClients, error) {
 	repo, repoClient, ossFuzzClient, ciiClient, vulnsClient, err := checker.GetClients(
 		dCtx.ctx, dSrcRepo, "", dCtx.logger,
 	)
 	if err != nil {
-		return fmt.Errorf("error getting the github repo and clients: %w", err)
+		return nil, fmt.Errorf("error getting the github repo and clients: %w", err)
 	}
-	dCtx.ghRepo = repo
-	dCtx.ghRepoClient = repoClient
+	c := &scorecardClients{repo: repo, repoClient: repoClient}
 	// If the caller doesn't specify the checks to run, run all the checks and return all the clients.
 	if dCtx.checkNamesToRun == nil || len(dCtx.checkNamesToRun) == 0 {
-		dCtx.ossFuzzClient, dCtx.ciiClient, dCtx.vulnsClient = ossFuzzClient, ciiClient, vulnsClient
-		return nil
+		c.ossFuzzClient, c.ciiClient, c.vulnsClient = ossFuzzClient, ciiClient, vulnsClient
+		return c, nil
 	}
 	for _, cn := range dCtx.checkNamesToRun {
 		switch cn {
 		case checks.CheckFuzzing:
-			dCtx.ossFuzzClient = ossFuzzClient
+			c.ossFuzzClient = ossFuzzClient
 		case checks.CheckCIIBestPractices:
-			dCtx.ciiClient = ciiClient
+			c.ciiClient = ciiClient
 		case checks.CheckVulnerabilities:
-			dCtx.vulnsClient = vulnsClient
+			c.vulnsClient = vulnsClient
 		}
 	}
-	return nil
+	return c, nil
+}
+
+// runScorecard initializes the repo and clients of the source repository of a dependency, and runs the checks.
+func runScorecard(
+	dCtx *dependencydiffContext, dSrcRepo string, checksToRun checker.CheckNameToFnMap,
+) (scpkg.ScorecardResult, error) {
+	c, err := initRepoAndClientByChecks(dCtx, dSrcRepo)
+	if err != nil {
+		return scpkg.ScorecardResult{}, fmt.Errorf("error init repo and clients: %w", err)
+	}
+	// TODO (#2064): use the Scorecare REST API to retrieve the Scorecard result statelessly.
+	result, err := scpkg.RunScorecards(
+		dCtx.ctx,
+		c.repo,
+		// TODO (#2065): In future versions, ideally, this should be
+		// the commitSHA corresponding to d.Version instead of HEAD.
+		clients.HeadSHA,
+		checksToRun,
+		c.repoClient,
+		c.ossFuzzClient,
+		c.ciiClient,
+		c.vulnsClient,
+	)
+	if err != nil {
+		return scpkg.ScorecardResult{}, fmt.Errorf("RunScorecards: %w", err)
+	}
+	return result, nil
 }
 
 func getScorecardCheckResults(dCtx *dependencydiffContext) error {
@@ -126,14 +203,18 @@ func getScorecardCheckResults(dCtx *dependencydiffContext) error {
 	if err != nil {
 		return fmt.Errorf("error init scorecard checks: %w", err)
 	}
-	for _, d := range dCtx.dependencydiffs {
-		depCheckResult := pkg.DependencyCheckResult{
+	dCtx.results = make([]pkg.DependencyCheckResult, len(dCtx.dependencydiffs))
+	toRun := []int{}
+	for i, d := range dCtx.dependencydiffs {
+		dCtx.results[i] = pkg.DependencyCheckResult{
 			PackageURL:       d.PackageURL,
 			SourceRepository: d.SourceRepository,
 			ChangeType:       d.ChangeType,
 			ManifestPath:     d.ManifestPath,
 			Ecosystem:        d.Ecosystem,
 			Version:          d.Version,
+			License:          d.License,
+			Vulnerabilities:  normalizeVulnerabilities(d.Vulnerabilities),
 			Name:             d.Name,
 		}
 		// Run the checks on all types if (1) the type is found in changeTypesToCheck or (2) no types are specified.
@@ -141,45 +222,73 @@ func getScorecardCheckResults(dCtx *dependencydiffContext) error {
 			(dCtx.changeTypesToCheck == nil || len(dCtx.changeTypesToCheck) == 0)
 		// For now we skip those without source repo urls.
 		// TODO (#2063): use the BigQuery dataset to supplement null source repo URLs to fetch the Scorecard results for them.
-		if d.SourceRepository != nil && TypeFoundOrNoneGiven {
-			// Initialize the repo and client(s) corresponding to the checks to run.
-			err = initRepoAndClientByChecks(dCtx, *d.SourceRepository)
-			if err != nil {
-				return fmt.Errorf("error init repo and clients: %w", err)
-			}
-
-			// Run scorecard on those types of dependencies that the caller would like to check.
-			// If the input map changeTypesToCheck is empty, by default, we run the checks for all valid types.
-			// TODO (#2064): use the Scorecare REST API to retrieve the Scorecard result statelessly.
-			scorecardResult, err := scpkg.RunScorecards(
-				dCtx.ctx,
-				dCtx.ghRepo,
-				// TODO (#2065): In future versions, ideally, this should be
-				// the commitSHA corresponding to d.Version instead of HEAD.
-				clients.HeadSHA,
-				checksToRun,
-				dCtx.ghRepoClient,
-				dCtx.ossFuzzClient,
-				dCtx.ciiClient,
-				dCtx.vulnsClient,
-			)
-			// If the run fails, we leave the current dependency scorecard result empty and record the error
-			// rather than letting the entire API return nil since we still expect results for other dependencies.
-			if err != nil {
-				wrappedErr := sce.WithMessage(sce.ErrScorecardInternal,
-					fmt.Sprintf("scorecard running failed for %s: %v", d.Name, err))
-				dCtx.logger.Error(wrappedErr, "")
-				depCheckResult.ScorecardResultWithError.Error = wrappedErr
-
-			} else { // Otherwise, we record the scorecard check results for this dependency.
-				depCheckResult.ScorecardResultWithError.ScorecardResult = &scorecardResult
-			}
+		switch {
+		case !TypeFoundOrNoneGiven:
+			dCtx.results[i].Status = pkg.StatusSkippedChangeType
+		case d.SourceRepository == nil:
+			dCtx.results[i].Status = pkg.StatusSkippedNoSource
+		case dCtx.dryRun:
+			dCtx.results[i].Status = pkg.StatusPlanned
+		default:
+			toRun = append(toRun, i)
 		}
-		dCtx.results = append(dCtx.results, depCheckResult)
 	}
+	// Run scorecard on those types of dependencies that the caller would like to check, using at most
+	// dCtx.workers goroutines. Every goroutine only writes the results of the dependencies it takes.
+	// If the input map changeTypesToCheck is empty, by default, we run the checks for all valid types.
+	workers := dCtx.workers
+	if workers < 1 {
+		workers = 1
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(toRun); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				getScorecardCheckResult(dCtx, checksToRun, &dCtx.results[i])
+			}
+		}()
+	}
+	for _, i := range toRun {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
 	return nil
 }
 
+// getScorecardCheckResult gets the check results of a dependency from the cache, or runs the checks on it.
+func getScorecardCheckResult(
+	dCtx *dependencydiffContext, checksToRun checker.CheckNameToFnMap, depCheckResult *pkg.DependencyCheckResult,
+) {
+	source := *depCheckResult.SourceRepository
+	if cached := dCtx.cache.get(source, checksToRun); cached != nil {
+		depCheckResult.ScorecardResultWithError.ScorecardResult = cached
+		depCheckResult.Status = pkg.StatusEvaluated
+		return
+	}
+	scorecardResult, err := dCtx.runScorecard(dCtx, source, checksToRun)
+	// If the run fails, we leave the current dependency scorecard result empty and record the error
+	// rather than letting the entire API return nil since we still expect results for other dependencies.
+	if err != nil {
+		wrappedErr := sce.WithMessage(sce.ErrScorecardInternal,
+			fmt.Sprintf("scorecard running failed for %s: %v", depCheckResult.Name, err))
+		dCtx.logger.Error(wrappedErr, "")
+		depCheckResult.ScorecardResultWithError.Error = wrappedErr
+		depCheckResult.Status = pkg.StatusFailed
+		return
+	}
+	// Otherwise, we record the scorecard check results for this dependency.
+	depCheckResult.ScorecardResultWithError.ScorecardResult = &scorecardResult
+	depCheckResult.Status = pkg.StatusEvaluated
+	if err := dCtx.cache.put(source, checksToRun, &scorecardResult); err != nil {
+		// A result which cannot be cached is still reported.
+		dCtx.logger.Error(err, "")
+	}
+}
+
 func asPointer(s string) *string {
 	return &s
 }
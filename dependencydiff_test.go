@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	"github.com/ossf/scorecard/v4/checker"
+	"github.com/ossf/scorecard/v4/checks"
+	sclog "github.com/ossf/scorecard/v4/log"
+	scpkg "github.com/ossf/scorecard/v4/pkg"
+)
+
+func TestGetScorecardCheckResults_DryRun(t *testing.T) {
+	t.Parallel()
+	added, removed := pkg.Added, pkg.Removed
+	source := "github.com/ossf/scorecard"
+	dCtx := dependencydiffContext{
+		logger:             sclog.NewLogger(sclog.DefaultLevel),
+		ctx:                context.Background(),
+		changeTypesToCheck: map[pkg.ChangeType]bool{pkg.Added: true},
+		dryRun:             true,
+		dependencydiffs: []dependency{
+			{Name: "scored", ChangeType: &added, SourceRepository: &source},
+			{Name: "no-source", ChangeType: &added},
+			{Name: "removed", ChangeType: &removed, SourceRepository: &source},
+		},
+	}
+	// A dry run never initializes the clients nor runs Scorecard, which would fail without a token.
+	if err := getScorecardCheckResults(&dCtx); err != nil {
+		t.Fatalf("getScorecardCheckResults: %v", err)
+	}
+	want := map[string]pkg.DependencyStatus{
+		"scored":    pkg.StatusPlanned,
+		"no-source": pkg.StatusSkippedNoSource,
+		"removed":   pkg.StatusSkippedChangeType,
+	}
+	if len(dCtx.results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(dCtx.results), len(want))
+	}
+	for _, r := range dCtx.results {
+		if r.Status != want[r.Name] {
+			t.Errorf("%s has status %s, want %s", r.Name, r.Status, want[r.Name])
+		}
+		if r.ScorecardResultWithError.ScorecardResult != nil || r.ScorecardResultWithError.Error != nil {
+			t.Errorf("%s has a Scorecard result", r.Name)
+		}
+	}
+}
+
+func TestGetScorecardCheckResults_Workers(t *testing.T) {
+	t.Parallel()
+	added := pkg.Added
+	sources := []string{"github.com/owner/a", "github.com/owner/b", "github.com/owner/c", "github.com/owner/fail"}
+	deps := []dependency{}
+	for i := range sources {
+		deps = append(deps, dependency{Name: sources[i], ChangeType: &added, SourceRepository: &sources[i]})
+	}
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	release := make(chan struct{})
+	dCtx := dependencydiffContext{
+		logger:          sclog.NewLogger(sclog.DefaultLevel),
+		ctx:             context.Background(),
+		checkNamesToRun: []string{checks.CheckLicense},
+		workers:         2,
+		dependencydiffs: deps,
+		runScorecard: func(
+			dCtx *dependencydiffContext, dSrcRepo string, checksToRun checker.CheckNameToFnMap,
+		) (scpkg.ScorecardResult, error) {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			<-release
+			mu.Lock()
+			running--
+			mu.Unlock()
+			if strings.HasSuffix(dSrcRepo, "fail") {
+				return scpkg.ScorecardResult{}, errInvalid
+			}
+			return scpkg.ScorecardResult{Repo: scpkg.RepoInfo{Name: dSrcRepo}}, nil
+		},
+	}
+	go func() {
+		for range sources {
+			release <- struct{}{}
+		}
+	}()
+	if err := getScorecardCheckResults(&dCtx); err != nil {
+		t.Fatalf("getScorecardCheckResults: %v", err)
+	}
+	if maxRunning > dCtx.workers {
+		t.Errorf("got %d concurrent runs, want at most %d", maxRunning, dCtx.workers)
+	}
+	// The results keep the order of the dependencies.
+	for i, r := range dCtx.results {
+		if r.Name != sources[i] {
+			t.Fatalf("result %d is %s, want %s", i, r.Name, sources[i])
+		}
+		if r.Name == "github.com/owner/fail" {
+			if r.Status != pkg.StatusFailed || r.ScorecardResultWithError.Error == nil {
+				t.Errorf("%s has status %s, want %s with an error", r.Name, r.Status, pkg.StatusFailed)
+			}
+			continue
+		}
+		if r.Status != pkg.StatusEvaluated || r.ScorecardResultWithError.ScorecardResult.Repo.Name != r.Name {
+			t.Errorf("%s has status %s, want %s with its result", r.Name, r.Status, pkg.StatusEvaluated)
+		}
+	}
+}
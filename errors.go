@@ -4,6 +4,8 @@ import "errors"
 
 // static Errors for mapping
 var (
-	errMappingNotFound = errors.New("ecosystem mapping not found")
-	errInvalid         = errors.New("invalid")
+	errMappingNotFound  = errors.New("ecosystem mapping not found")
+	errInvalid          = errors.New("invalid")
+	errPolicyFailure    = errors.New("dependency changes fail the policy")
+	errUnexpectedStatus = errors.New("unexpected status")
 )
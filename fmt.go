@@ -2,154 +2,511 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"sort"
+	"strings"
 
 	"github.com/aidenwang9867/depdiffvis/pkg"
 	"github.com/ossf/scorecard/v4/checker"
 	docs "github.com/ossf/scorecard/v4/docs/checks"
+	"github.com/ossf/scorecard/v4/log"
 )
 
-type scoreAndDependencyName struct {
-	aggregateScore float64
-	dependencyName string
+// changeSections are the sections of the markdown report, one per change type, in order.
+var changeSections = []struct {
+	changeType pkg.ChangeType
+	title      string
+}{
+	{changeType: pkg.Added, title: ":sparkles: Added dependencies"},
+	{changeType: pkg.Updated, title: ":arrow_up: Updated dependencies"},
+	{changeType: pkg.Removed, title: ":wastebasket: Removed dependencies"},
 }
 
-func PrintDependencies(deps []pkg.DependencyCheckResult) {
-	for _, d := range deps {
-		fmt.Println(*d.Ecosystem, d.Name, *d.Version, *d.ChangeType)
-		if d.PackageURL != nil && *d.PackageURL != "" {
-			fmt.Println(*d.PackageURL)
-		} else {
-			fmt.Println("empty package url")
-		}
-		if d.SourceRepository != nil && *d.SourceRepository != "" {
-			fmt.Println(*d.SourceRepository)
-		} else {
-			fmt.Println("empty src url")
-		}
-		fmt.Printf("===================\n\n")
-	}
+// markdownLimit limits the size of the markdown report, e.g. to fit in a GitHub comment.
+type markdownLimit struct {
+	// maxBytes is the maximum size of the report in bytes, where 0 means unlimited.
+	maxBytes int
+
+	// fullReportFile is the file to write the full report to when it exceeds maxBytes, which is referenced
+	// from the truncated report. The full report is not written if it is empty.
+	fullReportFile string
 }
 
-func SprintDependencyChecksToMarkdown(dChecks []pkg.DependencyCheckResult) (*string, error) {
-	// Use maps to reduce lookup times. Use pointers as values to save space.
-	added := map[string]pkg.DependencyCheckResult{}
-	removed := map[string]pkg.DependencyCheckResult{}
-	for _, d := range dChecks {
-		if d.ChangeType != nil {
-			switch *d.ChangeType {
-			case pkg.Added:
-				added[d.Name] = d
-			case pkg.Removed:
-				removed[d.Name] = d
-			}
-			// The current data source GitHub Dependency Review won't give the updated dependencies,
-			// so we need to find them manually by checking the added/removed maps.
-		}
+// SprintDependencyChecksToMarkdown renders the dependency changes as markdown. Each change type gets a table
+// with a column per executed check, followed by a collapsible block per dependency listing the check details
+// at the given log level, and the report ends with the vulnerability, license and policy sections.
+// If the report exceeds the limit, only the dependencies most at risk are rendered and the others are
+// summarized as counts per ecosystem.
+func SprintDependencyChecksToMarkdown(
+	dChecks []pkg.DependencyCheckResult, logLevel log.Level, checkDocs docs.Doc, policy *pkg.DependencydiffPolicy,
+	limit markdownLimit,
+) (*string, error) {
+	changes := scoredChangesOf(dChecks, checkDocs, policy)
+	evaluation, err := pkg.EvaluateDependencydiffPolicy(dChecks, policy, checkDocs)
+	if err != nil {
+		return nil, fmt.Errorf("error evaluating the policy: %w", err)
 	}
-	// Sort dependencies by their aggregate scores in descending orders.
-	addedSortKeys, err := getDependencySortKeys(added)
+	results, err := dependencyChangesToMarkdown(changes, evaluation, logLevel, checkDocs)
 	if err != nil {
 		return nil, err
 	}
-	removedSortKeys, err := getDependencySortKeys(removed)
+	if len(changes) > 0 {
+		graph, err := mermaidGraphToMarkdown(dChecks, checkDocs, policy)
+		if err != nil {
+			return nil, err
+		}
+		results += graph
+	}
+	if limit.maxBytes <= 0 || len(results) <= limit.maxBytes {
+		return &results, nil
+	}
+	note := fmt.Sprintf(
+		"> :warning: The report exceeds %d bytes, only the dependencies most at risk are listed.\n", limit.maxBytes,
+	)
+	if limit.fullReportFile != "" {
+		if err := os.WriteFile(limit.fullReportFile, []byte(results), 0o600); err != nil {
+			return nil, fmt.Errorf("error writing the full report: %w", err)
+		}
+		note += fmt.Sprintf("> The full report is available in `%s`.\n", limit.fullReportFile)
+	}
+	note += "\n"
+	truncated, err := truncatedChangesToMarkdown(changes, evaluation, logLevel, checkDocs, limit.maxBytes-len(note))
 	if err != nil {
 		return nil, err
 	}
+	results = note + truncated
+	if len(results) > limit.maxBytes {
+		// Not even the note and the verdict fit, so they are cut at the limit.
+		results = strings.ToValidUTF8(results[:limit.maxBytes], "")
+	}
+	return &results, nil
+}
+
+// scoredChangesOf pairs the dependency changes with their aggregate scores, sorted in descending order.
+func scoredChangesOf(
+	dChecks []pkg.DependencyCheckResult, checkDocs docs.Doc, policy *pkg.DependencydiffPolicy,
+) []scoredChange {
+	changes := []scoredChange{}
+	for _, c := range pkg.PairDependencyChanges(dChecks) {
+		changes = append(changes, scoredChange{
+			change:         c,
+			aggregateScore: pkg.AggregateScoreOf(changedDependency(c), checkDocs, policy),
+		})
+	}
 	sort.SliceStable(
-		addedSortKeys,
-		func(i, j int) bool { return addedSortKeys[i].aggregateScore > addedSortKeys[j].aggregateScore },
-	)
-	sort.SliceStable(
-		removedSortKeys,
-		func(i, j int) bool { return removedSortKeys[i].aggregateScore > removedSortKeys[j].aggregateScore },
+		changes,
+		func(i, j int) bool { return changes[i].aggregateScore > changes[j].aggregateScore },
 	)
+	return changes
+}
+
+// mermaidGraphToMarkdown renders the graph of the dependency changes in a collapsible mermaid code block,
+// which GitHub renders as a diagram. It is left out of a truncated report.
+func mermaidGraphToMarkdown(
+	dChecks []pkg.DependencyCheckResult, checkDocs docs.Doc, policy *pkg.DependencydiffPolicy,
+) (string, error) {
+	var sb strings.Builder
+	if err := pkg.DependencydiffResultsAsMermaid(dChecks, checkDocs, policy, &sb); err != nil {
+		return "", fmt.Errorf("error rendering the dependency graph: %w", err)
+	}
+	return fmt.Sprintf(
+		"<details>\n<summary>:bar_chart: Dependency graph</summary>\n\n```mermaid\n%s```\n\n</details>\n\n",
+		sb.String(),
+	), nil
+}
+
+// dependencyChangesToMarkdown renders a section per change type followed by the policy evaluation.
+func dependencyChangesToMarkdown(
+	changes []scoredChange, evaluation *pkg.PolicyEvaluation, logLevel log.Level, checkDocs docs.Doc,
+) (string, error) {
+	sections := map[pkg.ChangeType][]scoredChange{}
+	for _, c := range changes {
+		sections[c.change.ChangeType] = append(sections[c.change.ChangeType], c)
+	}
 	results := ""
-	for _, key := range addedSortKeys {
-		dName := key.dependencyName
-		if _, ok := added[dName]; !ok {
+	for _, section := range changeSections {
+		if len(sections[section.changeType]) == 0 {
 			continue
 		}
-		current := addedTag()
-		if _, ok := removed[dName]; ok {
-			// Dependency in the added map also found in the removed map, indicating an updated one.
-			current += updatedTag()
+		current, err := changesToMarkdown(section.title, sections[section.changeType], logLevel, checkDocs)
+		if err != nil {
+			return "", err
 		}
-		current += scoreTag(key.aggregateScore)
-		new := added[dName]
-		current += fmt.Sprintf(
-			"%s @ %s (new) ",
-			new.Name, *new.Version,
-		)
-		if old, ok := removed[dName]; ok {
-			current += fmt.Sprintf(
-				"~~%s @ %s (removed)~~ ",
-				old.Name, *old.Version,
-			)
-		}
-		results += current + "\n\n"
-	}
-	for _, key := range removedSortKeys {
-		dName := key.dependencyName
-		if _, ok := added[dName]; ok {
-			// Skip updated ones.
-			continue
+		results += current
+	}
+	return results + policyEvaluationToMarkdown(evaluation), nil
+}
+
+// truncatedChangesToMarkdown renders as many changes as fit in maxBytes, prioritizing the dependencies
+// violating the policy and then those with the lowest scores. The omitted changes are counted per ecosystem.
+func truncatedChangesToMarkdown(
+	changes []scoredChange, evaluation *pkg.PolicyEvaluation, logLevel log.Level, checkDocs docs.Doc,
+	maxBytes int,
+) (string, error) {
+	prioritized := prioritizeChanges(changes, evaluation)
+	render := func(n int) (string, error) {
+		included := map[*pkg.DependencyCheckResult]bool{}
+		for _, c := range prioritized[:n] {
+			included[changedDependency(c.change)] = true
 		}
-		if _, ok := removed[dName]; !ok {
-			continue
+		// Keep the included changes in the order of the full report.
+		kept, omitted := []scoredChange{}, []scoredChange{}
+		for _, c := range changes {
+			if included[changedDependency(c.change)] {
+				kept = append(kept, c)
+			} else {
+				omitted = append(omitted, c)
+			}
+		}
+		results, err := dependencyChangesToMarkdown(kept, evaluation, logLevel, checkDocs)
+		if err != nil {
+			return "", err
+		}
+		return results + omittedChangesToMarkdown(omitted), nil
+	}
+	// Find the most changes fitting in the limit by a binary search, since the size grows with the changes.
+	low, high := 0, len(prioritized)
+	for low < high {
+		mid := (low + high + 1) / 2
+		results, err := render(mid)
+		if err != nil {
+			return "", err
+		}
+		if len(results) <= maxBytes {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	results, err := render(low)
+	if err != nil {
+		return "", err
+	}
+	verdict := fmt.Sprintf("### Policy verdict: `%s`\n\n", evaluation.Verdict)
+	if len(results) > maxBytes {
+		// The policy evaluation alone is too large, so only the verdict and the counts are given.
+		results = verdict + omittedChangesToMarkdown(changes)
+	}
+	if len(results) > maxBytes {
+		results = verdict
+	}
+	return results, nil
+}
+
+// prioritizeChanges orders changes by the worst policy verdict of their dependencies, then by ascending
+// aggregate scores with the inconclusive ones last.
+func prioritizeChanges(changes []scoredChange, evaluation *pkg.PolicyEvaluation) []scoredChange {
+	verdictRanks := map[pkg.Verdict]int{pkg.VerdictFail: 0, pkg.VerdictWarn: 1, pkg.VerdictPass: 2}
+	verdicts := map[*pkg.DependencyCheckResult]pkg.Verdict{}
+	for _, v := range evaluation.Violations {
+		if current, ok := verdicts[v.Dependency]; !ok || verdictRanks[v.Verdict] < verdictRanks[current] {
+			verdicts[v.Dependency] = v.Verdict
+		}
+	}
+	rankOf := func(c pkg.DependencyChange) int {
+		rank := verdictRanks[pkg.VerdictPass]
+		for _, d := range []*pkg.DependencyCheckResult{c.Old, c.New} {
+			if v, ok := verdicts[d]; ok && d != nil && verdictRanks[v] < rank {
+				rank = verdictRanks[v]
+			}
+		}
+		return rank
+	}
+	prioritized := make([]scoredChange, len(changes))
+	copy(prioritized, changes)
+	inconclusive := float64(checker.InconclusiveResultScore)
+	sort.SliceStable(prioritized, func(i, j int) bool {
+		ri, rj := rankOf(prioritized[i].change), rankOf(prioritized[j].change)
+		if ri != rj {
+			return ri < rj
+		}
+		si, sj := prioritized[i].aggregateScore, prioritized[j].aggregateScore
+		if (si == inconclusive) != (sj == inconclusive) {
+			return sj == inconclusive
 		}
-		current := removedTag()
-		current += scoreTag(key.aggregateScore)
-		old := removed[dName]
-		current += fmt.Sprintf(
-			"~~%s @ %s~~ ",
-			old.Name, *old.Version,
+		return si < sj
+	})
+	return prioritized
+}
+
+// omittedChangesToMarkdown counts the omitted changes per ecosystem and change type.
+func omittedChangesToMarkdown(omitted []scoredChange) string {
+	if len(omitted) == 0 {
+		return ""
+	}
+	counts := map[string]map[pkg.ChangeType]int{}
+	for _, c := range omitted {
+		ecosystem := "unknown"
+		if d := changedDependency(c.change); d.Ecosystem != nil && *d.Ecosystem != "" {
+			ecosystem = *d.Ecosystem
+		}
+		if counts[ecosystem] == nil {
+			counts[ecosystem] = map[pkg.ChangeType]int{}
+		}
+		counts[ecosystem][c.change.ChangeType]++
+	}
+	ecosystems := []string{}
+	for e := range counts {
+		ecosystems = append(ecosystems, e)
+	}
+	sort.Strings(ecosystems)
+	results := fmt.Sprintf("### %d more dependency changes omitted\n\n", len(omitted))
+	results += "| Ecosystem | Added | Updated | Removed |\n| --- | --- | --- | --- |\n"
+	for _, e := range ecosystems {
+		results += fmt.Sprintf(
+			"| %s | %d | %d | %d |\n",
+			escapeTableCell(e), counts[e][pkg.Added], counts[e][pkg.Updated], counts[e][pkg.Removed],
 		)
-		results += current + "\n\n"
 	}
-	return &results, nil
+	return results + "\n"
 }
 
-func getDependencySortKeys(dcMap map[string]pkg.DependencyCheckResult) ([]scoreAndDependencyName, error) {
-	checkDocs, err := docs.Read()
-	if err != nil {
-		return nil, fmt.Errorf("error getting the check docs: %w", err)
-	}
-	sortKeys := []scoreAndDependencyName{}
-	for k := range dcMap {
-		score := float64(checker.InconclusiveResultScore)
-		if dcMap[k].ScorecardResultWithError.ScorecardResult != nil {
-			aggregated, err := dcMap[k].ScorecardResultWithError.ScorecardResult.GetAggregateScore(checkDocs)
-			if err == nil {
-				score = aggregated
+// changesToMarkdown renders a table of the changes with a column per check run on any of them, and the
+// details of each dependency below the table.
+func changesToMarkdown(
+	title string, changes []scoredChange, logLevel log.Level, checkDocs docs.Doc,
+) (string, error) {
+	checkNames := []string{}
+	seen := map[string]bool{}
+	for _, c := range changes {
+		for _, check := range scorecardChecksOf(changedDependency(c.change)) {
+			if !seen[check.Name] {
+				seen[check.Name] = true
+				checkNames = append(checkNames, check.Name)
 			}
-			// Don't return the err immediately since we still want aggregate scores of other dependencies.
 		}
-		sortKeys = append(sortKeys, scoreAndDependencyName{
-			aggregateScore: score,
-			dependencyName: dcMap[k].Name,
-		})
 	}
-	return sortKeys, nil
+	sort.Strings(checkNames)
+	header := "| Dependency | Version | Score |"
+	separator := "| --- | --- | --- |"
+	for _, name := range checkNames {
+		doc, err := checkDocs.GetCheck(name)
+		if err != nil {
+			return "", fmt.Errorf("error getting the doc of check %s: %w", name, err)
+		}
+		header += fmt.Sprintf(" [%s](%s) |", name, doc.GetDocumentationURL(""))
+		separator += " --- |"
+	}
+	header += " Vulnerabilities |"
+	separator += " --- |"
+	results := fmt.Sprintf("### %s\n\n%s\n%s\n", title, header, separator)
+	for _, c := range changes {
+		d := changedDependency(c.change)
+		row := fmt.Sprintf(
+			"| %s | %s | %s |",
+			escapeTableCell(d.Name), escapeTableCell(versionChangeOf(c.change)), scoreCell(d, c.aggregateScore),
+		)
+		checkResults := map[string]checker.CheckResult{}
+		for _, check := range scorecardChecksOf(d) {
+			checkResults[check.Name] = check
+		}
+		for _, name := range checkNames {
+			row += fmt.Sprintf(" %s |", checkCell(checkResults, name, checkDocs))
+		}
+		row += fmt.Sprintf(" %d |", len(d.Vulnerabilities))
+		results += row + "\n"
+	}
+	results += "\n"
+	for _, c := range changes {
+		current, err := dependencyDetailsToMarkdown(changedDependency(c.change), logLevel, checkDocs)
+		if err != nil {
+			return "", err
+		}
+		results += current
+	}
+	return results, nil
 }
 
-func addedTag() string {
-	return fmt.Sprintf(":sparkles: **`" + "added" + "`** ")
+// dependencyDetailsToMarkdown renders a collapsible block listing the check results with their details, the
+// Scorecard error and the vulnerabilities of a dependency. It is empty if there is nothing to list.
+func dependencyDetailsToMarkdown(
+	d *pkg.DependencyCheckResult, logLevel log.Level, checkDocs docs.Doc,
+) (string, error) {
+	body := ""
+	if err := d.ScorecardResultWithError.Error; err != nil {
+		body += fmt.Sprintf(":x: Scorecard failed: %s\n\n", err)
+	}
+	scResult := d.ScorecardResultWithError.ScorecardResult
+	for _, check := range scorecardChecksOf(d) {
+		doc, err := checkDocs.GetCheck(check.Name)
+		if err != nil {
+			return "", fmt.Errorf("error getting the doc of check %s: %w", check.Name, err)
+		}
+		body += fmt.Sprintf(
+			"**[%s](%s)** `%s`: %s\n",
+			check.Name, doc.GetDocumentationURL(scResult.Scorecard.CommitSHA), checkScoreOf(check), check.Reason,
+		)
+		for i := range check.Details {
+			if m := pkg.DetailToString(&check.Details[i], logLevel); m != "" {
+				body += fmt.Sprintf("- %s\n", m)
+			}
+		}
+		body += "\n"
+	}
+	if len(d.Vulnerabilities) > 0 {
+		body += "**Vulnerabilities**\n" + vulnerabilitiesTag(d.Vulnerabilities) + fixSuggestionTag(d.FixSuggestion) + "\n\n"
+	}
+	if body == "" {
+		return "", nil
+	}
+	return fmt.Sprintf(
+		"<details>\n<summary>%s @ %s</summary>\n\n%s</details>\n\n", d.Name, versionOf(d), body,
+	), nil
 }
 
-func updatedTag() string {
-	return fmt.Sprintf("**`" + "updated" + "`** ")
+// policyEvaluationToMarkdown lists the vulnerabilities introduced, fixed and left unchanged by the dependency
+// changes, followed by the policy violations.
+func policyEvaluationToMarkdown(e *pkg.PolicyEvaluation) string {
+	results := ""
+	results += dependencyVulnerabilitiesToMarkdown(":x: Introduced vulnerabilities", e.Vulnerabilities.Introduced)
+	results += dependencyVulnerabilitiesToMarkdown(":white_check_mark: Fixed vulnerabilities", e.Vulnerabilities.Fixed)
+	results += dependencyVulnerabilitiesToMarkdown(":warning: Pre-existing vulnerabilities", e.Vulnerabilities.Unchanged)
+	results += licenseChangesToMarkdown(e.LicenseChanges)
+	if len(e.Violations) == 0 {
+		return results
+	}
+	results += fmt.Sprintf("### Policy verdict: `%s`\n\n", e.Verdict)
+	for _, v := range e.Violations {
+		results += fmt.Sprintf("- **`%s`** %s @ %s %s\n", v.Verdict, v.Dependency.Name, versionOf(v.Dependency), v.Message)
+	}
+	return results + "\n"
 }
 
-func removedTag() string {
-	return fmt.Sprintf("~~**`" + "removed" + "`**~~ ")
+func dependencyVulnerabilitiesToMarkdown(title string, dvs []pkg.DependencyVulnerability) string {
+	if len(dvs) == 0 {
+		return ""
+	}
+	results := fmt.Sprintf("### %s\n\n", title)
+	for _, dv := range dvs {
+		v := dv.Vulnerability
+		results += fmt.Sprintf(
+			"- **`%s`** [%s](%s) in %s @ %s: %s\n",
+			v.Severity, v.ID, v.SourceURL, dv.Dependency.Name, versionOf(dv.Dependency), v.Title,
+		)
+	}
+	return results + "\n"
 }
 
-func scoreTag(score float64) string {
-	switch score {
-	case float64(checker.InconclusiveResultScore):
+func licenseChangesToMarkdown(changes []pkg.LicenseChange) string {
+	if len(changes) == 0 {
 		return ""
+	}
+	results := "### :scroll: License changes\n\n"
+	for _, lc := range changes {
+		results += fmt.Sprintf(
+			"- %s @ %s: `%s` → `%s`\n",
+			lc.Change.New.Name, versionOf(lc.Change.New), licenseOrUnknown(lc.Old), licenseOrUnknown(lc.New),
+		)
+	}
+	return results + "\n"
+}
+
+func licenseOrUnknown(license string) string {
+	if license == "" {
+		return "unknown"
+	}
+	return license
+}
+
+func versionOf(d *pkg.DependencyCheckResult) string {
+	if d.Version == nil {
+		return "unknown version"
+	}
+	return *d.Version
+}
+
+type scoredChange struct {
+	change         pkg.DependencyChange
+	aggregateScore float64
+}
+
+// changedDependency is the dependency at HEAD, or at BASE if the dependency is removed.
+func changedDependency(c pkg.DependencyChange) *pkg.DependencyCheckResult {
+	if c.New != nil {
+		return c.New
+	}
+	return c.Old
+}
+
+func scorecardChecksOf(d *pkg.DependencyCheckResult) []checker.CheckResult {
+	if d.ScorecardResultWithError.ScorecardResult == nil {
+		return nil
+	}
+	return d.ScorecardResultWithError.ScorecardResult.Checks
+}
+
+func versionChangeOf(c pkg.DependencyChange) string {
+	switch c.ChangeType {
+	case pkg.Updated:
+		if c.Old != nil {
+			return fmt.Sprintf("%s → %s", versionOf(c.Old), versionOf(c.New))
+		}
+		return versionOf(c.New)
+	case pkg.Removed:
+		return fmt.Sprintf("~~%s~~", versionOf(c.Old))
 	default:
-		return fmt.Sprintf("`Scorecard Score: %.1f` ", score)
+		return versionOf(changedDependency(c))
+	}
+}
+
+// scoreCell shows the aggregate score of a dependency, or its status if the checks were not run.
+func scoreCell(d *pkg.DependencyCheckResult, score float64) string {
+	if d.ScorecardResultWithError.ScorecardResult == nil {
+		if d.Status == "" {
+			return "-"
+		}
+		return fmt.Sprintf("_%s_", d.Status)
+	}
+	if score == float64(checker.InconclusiveResultScore) {
+		return "?"
+	}
+	return fmt.Sprintf("**%.1f**", score)
+}
+
+// checkCell shows the score of a check linking to its documentation, with the reason as a tooltip.
+func checkCell(checkResults map[string]checker.CheckResult, name string, checkDocs docs.Doc) string {
+	check, ok := checkResults[name]
+	if !ok {
+		return "-"
 	}
+	url := ""
+	if doc, err := checkDocs.GetCheck(name); err == nil {
+		url = doc.GetDocumentationURL("")
+	}
+	reason := strings.ReplaceAll(check.Reason, `"`, `\"`)
+	return escapeTableCell(fmt.Sprintf("[%s](%s \"%s\")", checkScoreOf(check), url, reason))
+}
+
+func checkScoreOf(check checker.CheckResult) string {
+	if check.Score == checker.InconclusiveResultScore {
+		return "?"
+	}
+	return fmt.Sprintf("%d", check.Score)
+}
+
+func escapeTableCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", " ")
+}
+
+func vulnerabilitiesTag(vulns []pkg.Vulnerability) string {
+	result := ""
+	for _, v := range vulns {
+		result += fmt.Sprintf("\n- :warning: **`%s`** [%s](%s) %s", v.Severity, v.ID, v.SourceURL, v.Title)
+		if v.FixedVersion != "" {
+			result += fmt.Sprintf(" (fixed in `%s`)", v.FixedVersion)
+		}
+	}
+	return result
+}
+
+func fixSuggestionTag(fix *pkg.FixSuggestion) string {
+	if fix == nil {
+		return ""
+	}
+	if fix.SameMajor {
+		return fmt.Sprintf("\n\n:bulb: Upgrade to `%s` to fix the known vulnerabilities.", fix.Version)
+	}
+	return fmt.Sprintf(
+		"\n\n:bulb: Upgrade to `%s` to fix the known vulnerabilities, which is a new major version.", fix.Version,
+	)
 }
@@ -0,0 +1,575 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	"github.com/ossf/scorecard/v4/checker"
+	"github.com/ossf/scorecard/v4/checks"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sce "github.com/ossf/scorecard/v4/errors"
+	"github.com/ossf/scorecard/v4/log"
+	scpkg "github.com/ossf/scorecard/v4/pkg"
+)
+
+const (
+	testLicenseURL    = "https://github.com/ossf/scorecard/blob/main/docs/checks.md#license"
+	testCodeReviewURL = "https://github.com/ossf/scorecard/blob/main/docs/checks.md#code-review"
+	testGraphSummary  = ":bar_chart: Dependency graph"
+)
+
+// testResults are the dependency changes shared by the tests of the markdown report and of the terminal table:
+// the update of lib, fixing GHSA-low, and the addition of vulnerable, introducing GHSA-high, which are
+// evaluated; the addition of unreachable, on which the checks failed; and the addition of a dependency without a
+// source repository, whose name needs escaping, and the removal of gone, which are skipped.
+func testResults() []pkg.DependencyCheckResult {
+	added, removed := pkg.Added, pkg.Removed
+	v1, v2, goMod, packageJSON, mit, gpl := "1.0.0", "2.0.0", "go.mod", "package.json", "MIT", "GPL-3.0-only"
+	goEcosystem, npm := "Go", "npm"
+	lib, vulnerable, unreachable := "github.com/owner/lib", "github.com/owner/vulnerable", "github.com/owner/unreachable"
+	scorecardResult := func(repo, commit string, results ...checker.CheckResult) *scpkg.ScorecardResult {
+		return &scpkg.ScorecardResult{
+			Repo:      scpkg.RepoInfo{Name: repo, CommitSHA: commit},
+			Scorecard: scpkg.ScorecardInfo{Version: "v4.4.0", CommitSHA: "2222222"},
+			Date:      time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC),
+			Checks:    results,
+		}
+	}
+	return []pkg.DependencyCheckResult{
+		{
+			Name: "lib", ChangeType: &removed, Version: &v1, Ecosystem: &goEcosystem, ManifestPath: &goMod,
+			SourceRepository: &lib, License: &mit, Status: pkg.StatusEvaluated,
+			ScorecardResultWithError: pkg.ScorecardResultWithError{
+				ScorecardResult: scorecardResult(lib, "1111111", checker.CheckResult{
+					Name: checks.CheckLicense, Score: 7, Reason: "license file detected",
+				}),
+			},
+			Vulnerabilities: []pkg.Vulnerability{
+				{Source: "GHSA", ID: "GHSA-low", Severity: pkg.Low, Title: "fixed by the update"},
+			},
+		},
+		{
+			Name: "lib", ChangeType: &added, Version: &v2, Ecosystem: &goEcosystem, ManifestPath: &goMod,
+			SourceRepository: &lib, License: &mit, Status: pkg.StatusEvaluated,
+			ScorecardResultWithError: pkg.ScorecardResultWithError{
+				ScorecardResult: scorecardResult(lib, "3333333",
+					checker.CheckResult{Name: checks.CheckLicense, Score: 9, Reason: "license file detected"},
+					checker.CheckResult{
+						Name: checks.CheckCodeReview, Score: checker.InconclusiveResultScore, Reason: "internal error",
+					},
+				),
+			},
+		},
+		{
+			Name: "vulnerable", ChangeType: &added, Version: &v1, Ecosystem: &npm, ManifestPath: &packageJSON,
+			SourceRepository: &vulnerable, License: &gpl, Status: pkg.StatusEvaluated,
+			ScorecardResultWithError: pkg.ScorecardResultWithError{
+				ScorecardResult: scorecardResult(vulnerable, "4444444", checker.CheckResult{
+					Name: checks.CheckLicense, Score: 0, Reason: "license file not detected",
+					Details: []checker.CheckDetail{
+						{Type: checker.DetailWarn, Msg: checker.LogMessage{Text: "license file not found"}},
+					},
+				}),
+			},
+			Vulnerabilities: []pkg.Vulnerability{
+				{
+					Source: "GHSA", ID: "GHSA-high", Severity: pkg.High, Title: "prototype pollution",
+					SourceURL: "https://github.com/advisories/GHSA-high", FixedVersion: "1.0.1",
+				},
+			},
+			FixSuggestion: &pkg.FixSuggestion{Version: "1.0.1", SameMajor: true},
+		},
+		{
+			Name: "unreachable", ChangeType: &added, Version: &v1, Ecosystem: &npm, ManifestPath: &packageJSON,
+			SourceRepository: &unreachable, Status: pkg.StatusFailed,
+			ScorecardResultWithError: pkg.ScorecardResultWithError{
+				Error: sce.WithMessage(sce.ErrRepoUnreachable, "not found"),
+			},
+		},
+		{
+			Name: `=HYPERLINK("<script>")`, ChangeType: &added, Version: &v1, Ecosystem: &npm,
+			ManifestPath: &packageJSON, Status: pkg.StatusSkippedNoSource,
+		},
+		{
+			Name: "gone", ChangeType: &removed, Version: &v1, Ecosystem: &npm, ManifestPath: &packageJSON,
+			Status: pkg.StatusSkippedChangeType,
+		},
+	}
+}
+
+// markdownReport is the structure of a parsed markdown report.
+type markdownReport struct {
+	headings []string
+	// tables are the rows of the table of each heading, including the header, as the text of their cells.
+	tables map[string][][]string
+	// details are the non-empty lines of the collapsed blocks by their summaries.
+	details map[string][]string
+}
+
+// parseMarkdownReport parses the headings, tables and collapsed blocks of a markdown report. Blocks which
+// are not collapsed, i.e. opened with attributes, are not recognized.
+func parseMarkdownReport(t *testing.T, report string) *markdownReport {
+	t.Helper()
+	parsed := &markdownReport{tables: map[string][][]string{}, details: map[string][]string{}}
+	lines := strings.Split(report, "\n")
+	heading := ""
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case line == "<details>":
+			if i+1 == len(lines) || !strings.HasPrefix(lines[i+1], "<summary>") {
+				t.Fatalf("got no summary after line %d", i)
+			}
+			i++
+			summary := strings.TrimSuffix(strings.TrimPrefix(lines[i], "<summary>"), "</summary>")
+			body := []string{}
+			for i++; i < len(lines) && lines[i] != "</details>"; i++ {
+				if lines[i] != "" {
+					body = append(body, lines[i])
+				}
+			}
+			if i == len(lines) {
+				t.Fatalf("got no end of the details of %s", summary)
+			}
+			parsed.details[summary] = body
+		case strings.HasPrefix(line, "### "):
+			heading = strings.TrimPrefix(line, "### ")
+			parsed.headings = append(parsed.headings, heading)
+		case strings.HasPrefix(line, "| --- |"):
+		case strings.HasPrefix(line, "| "):
+			cells := strings.Split(strings.TrimSuffix(strings.TrimPrefix(line, "| "), " |"), " | ")
+			parsed.tables[heading] = append(parsed.tables[heading], cells)
+		}
+	}
+	return parsed
+}
+
+func TestSprintDependencyChecksToMarkdown(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	policy := &pkg.DependencydiffPolicy{FailOnSeverity: pkg.High}
+	//nolint
+	tests := []struct {
+		name         string
+		results      []pkg.DependencyCheckResult
+		wantHeadings []string
+		wantTables   map[string][][]string
+		wantDetails  map[string][]string
+	}{
+		{
+			name:    "changes",
+			results: testResults(),
+			wantHeadings: []string{
+				":sparkles: Added dependencies",
+				":arrow_up: Updated dependencies",
+				":wastebasket: Removed dependencies",
+				":x: Introduced vulnerabilities",
+				":white_check_mark: Fixed vulnerabilities",
+				"Policy verdict: `fail`",
+			},
+			// Dependencies are sorted by their aggregate scores, and have a column per check run on any of them
+			// in the section.
+			wantTables: map[string][][]string{
+				":sparkles: Added dependencies": {
+					{"Dependency", "Version", "Score", "[License](" + testLicenseURL + ")", "Vulnerabilities"},
+					{"vulnerable", "1.0.0", "**0.0**", "[0](" + testLicenseURL + ` "license file not detected")`, "1"},
+					{"unreachable", "1.0.0", "_failed_", "-", "0"},
+					{`=HYPERLINK("<script>")`, "1.0.0", "_skipped-no-source_", "-", "0"},
+				},
+				":arrow_up: Updated dependencies": {
+					{
+						"Dependency", "Version", "Score", "[Code-Review](" + testCodeReviewURL + ")",
+						"[License](" + testLicenseURL + ")", "Vulnerabilities",
+					},
+					{
+						"lib", "1.0.0 → 2.0.0", "**9.0**", "[?](" + testCodeReviewURL + ` "internal error")`,
+						"[9](" + testLicenseURL + ` "license file detected")`, "0",
+					},
+				},
+				":wastebasket: Removed dependencies": {
+					{"Dependency", "Version", "Score", "Vulnerabilities"},
+					{"gone", "~~1.0.0~~", "_skipped-change-type_", "0"},
+				},
+			},
+			// Only the dependencies with check results, a Scorecard error or vulnerabilities have details.
+			wantDetails: map[string][]string{
+				"vulnerable @ 1.0.0": {
+					"**[License](https://github.com/ossf/scorecard/blob/2222222/docs/checks.md#license)** `0`: " +
+						"license file not detected",
+					"- Warn: license file not found",
+					"**Vulnerabilities**",
+					"- :warning: **`HIGH`** [GHSA-high](https://github.com/advisories/GHSA-high) prototype pollution " +
+						"(fixed in `1.0.1`)",
+					":bulb: Upgrade to `1.0.1` to fix the known vulnerabilities.",
+				},
+				"unreachable @ 1.0.0": {":x: Scorecard failed: repo unreachable: not found"},
+				"lib @ 2.0.0": {
+					"**[License](https://github.com/ossf/scorecard/blob/2222222/docs/checks.md#license)** `9`: " +
+						"license file detected",
+					"**[Code-Review](https://github.com/ossf/scorecard/blob/2222222/docs/checks.md#code-review)** " +
+						"`?`: internal error",
+				},
+			},
+		},
+		{
+			name:         "removed dependencies",
+			results:      testResults()[5:],
+			wantHeadings: []string{":wastebasket: Removed dependencies"},
+			wantTables: map[string][][]string{
+				":wastebasket: Removed dependencies": {
+					{"Dependency", "Version", "Score", "Vulnerabilities"},
+					{"gone", "~~1.0.0~~", "_skipped-change-type_", "0"},
+				},
+			},
+			wantDetails: map[string][]string{},
+		},
+		{
+			name:         "no changes",
+			results:      []pkg.DependencyCheckResult{},
+			wantHeadings: nil,
+			wantTables:   map[string][][]string{},
+			wantDetails:  map[string][]string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			report, err := SprintDependencyChecksToMarkdown(
+				tt.results, log.DefaultLevel, checkDocs, policy, markdownLimit{},
+			)
+			if err != nil {
+				t.Fatalf("SprintDependencyChecksToMarkdown: %v", err)
+			}
+			got := parseMarkdownReport(t, *report)
+			if !reflect.DeepEqual(got.headings, tt.wantHeadings) {
+				t.Errorf("got headings %q, want %q", got.headings, tt.wantHeadings)
+			}
+			if !reflect.DeepEqual(got.tables, tt.wantTables) {
+				t.Errorf("got tables\n%q\nwant\n%q", got.tables, tt.wantTables)
+			}
+			// The dependency graph follows the changes.
+			if graph, found := got.details[testGraphSummary]; found != (len(tt.results) > 0) ||
+				found && (graph[0] != "```mermaid" || graph[1] != "flowchart LR") {
+				t.Errorf("got dependency graph %q", graph)
+			}
+			delete(got.details, testGraphSummary)
+			if !reflect.DeepEqual(got.details, tt.wantDetails) {
+				t.Errorf("got details\n%q\nwant\n%q", got.details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+// namesOf returns the names of the changed dependencies.
+func namesOf(changes []scoredChange) []string {
+	names := []string{}
+	for _, c := range changes {
+		names = append(names, changedDependency(c.change).Name)
+	}
+	return names
+}
+
+func TestPrioritizeChanges(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	changes := scoredChangesOf(testResults(), checkDocs, nil)
+	dependencies := map[string]*pkg.DependencyCheckResult{}
+	for _, c := range changes {
+		dependencies[changedDependency(c.change).Name] = changedDependency(c.change)
+	}
+	//nolint
+	tests := []struct {
+		name       string
+		violations []pkg.PolicyViolation
+		want       []string
+	}{
+		{
+			// Ascending scores, with the inconclusive ones last in the order of the report.
+			name:       "no violations",
+			violations: nil,
+			want:       []string{"vulnerable", "lib", "unreachable", `=HYPERLINK("<script>")`, "gone"},
+		},
+		{
+			name: "violations first",
+			violations: []pkg.PolicyViolation{
+				{Dependency: dependencies["gone"], Verdict: pkg.VerdictWarn},
+				{Dependency: dependencies["lib"], Verdict: pkg.VerdictFail},
+			},
+			want: []string{"lib", "gone", "vulnerable", "unreachable", `=HYPERLINK("<script>")`},
+		},
+		{
+			name: "worst verdict of a dependency",
+			violations: []pkg.PolicyViolation{
+				{Dependency: dependencies["unreachable"], Verdict: pkg.VerdictWarn},
+				{Dependency: dependencies["gone"], Verdict: pkg.VerdictWarn},
+				{Dependency: dependencies["gone"], Verdict: pkg.VerdictFail},
+			},
+			want: []string{"gone", "unreachable", "vulnerable", "lib", `=HYPERLINK("<script>")`},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := prioritizeChanges(changes, &pkg.PolicyEvaluation{Violations: tt.violations})
+			if !reflect.DeepEqual(namesOf(got), tt.want) {
+				t.Errorf("got changes %q, want %q", namesOf(got), tt.want)
+			}
+		})
+	}
+	// The changes are left in the order of the report.
+	if got := namesOf(changes); got[0] != "lib" {
+		t.Errorf("got changes %q reordered", got)
+	}
+}
+
+func TestOmittedChangesToMarkdown(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	added := pkg.Added
+	noEcosystem := pkg.DependencyCheckResult{Name: "no-ecosystem", ChangeType: &added}
+	//nolint
+	tests := []struct {
+		name         string
+		results      []pkg.DependencyCheckResult
+		wantHeadings []string
+		wantTables   map[string][][]string
+	}{
+		{
+			name:         "no omitted changes",
+			results:      nil,
+			wantHeadings: nil,
+			wantTables:   map[string][][]string{},
+		},
+		{
+			name:         "omitted changes",
+			results:      append(testResults(), noEcosystem),
+			wantHeadings: []string{"6 more dependency changes omitted"},
+			wantTables: map[string][][]string{
+				"6 more dependency changes omitted": {
+					{"Ecosystem", "Added", "Updated", "Removed"},
+					{"Go", "0", "1", "0"},
+					{"npm", "3", "0", "1"},
+					{"unknown", "1", "0", "0"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := parseMarkdownReport(t, omittedChangesToMarkdown(scoredChangesOf(tt.results, checkDocs, nil)))
+			if !reflect.DeepEqual(got.headings, tt.wantHeadings) {
+				t.Errorf("got headings %q, want %q", got.headings, tt.wantHeadings)
+			}
+			if !reflect.DeepEqual(got.tables, tt.wantTables) {
+				t.Errorf("got tables\n%q\nwant\n%q", got.tables, tt.wantTables)
+			}
+		})
+	}
+}
+
+func TestSprintDependencyChecksToMarkdown_Limit(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	results := testResults()
+	policy := &pkg.DependencydiffPolicy{FailOnSeverity: pkg.High}
+	full, err := SprintDependencyChecksToMarkdown(results, log.DefaultLevel, checkDocs, policy, markdownLimit{})
+	if err != nil {
+		t.Fatalf("SprintDependencyChecksToMarkdown: %v", err)
+	}
+	addedTable := func(names ...string) [][]string {
+		rows := [][]string{{"Dependency", "Version", "Score", "[License](" + testLicenseURL + ")", "Vulnerabilities"}}
+		for _, name := range names {
+			rows = append(rows, parseMarkdownReport(t, *full).tables[":sparkles: Added dependencies"][len(rows)])
+			if rows[len(rows)-1][0] != name {
+				t.Fatalf("got added dependency %s, want %s", rows[len(rows)-1][0], name)
+			}
+		}
+		return rows
+	}
+	evaluationHeadings := []string{
+		":x: Introduced vulnerabilities", ":white_check_mark: Fixed vulnerabilities", "Policy verdict: `fail`",
+	}
+	//nolint
+	tests := []struct {
+		name     string
+		maxBytes int
+		// truncated tells whether the report is truncated, starting with a note.
+		truncated bool
+		// fullReport tells whether the full report is written, so that the note references it.
+		fullReport   bool
+		wantHeadings []string
+		wantTables   map[string][][]string
+		wantDetails  []string
+	}{
+		{
+			name:         "fits",
+			maxBytes:     len(*full),
+			wantHeadings: parseMarkdownReport(t, *full).headings,
+			wantTables:   parseMarkdownReport(t, *full).tables,
+			wantDetails:  []string{"vulnerable @ 1.0.0", "unreachable @ 1.0.0", "lib @ 2.0.0", testGraphSummary},
+		},
+		{
+			// The dependency graph is left out first.
+			name:         "without the graph",
+			maxBytes:     len(*full) - 1,
+			truncated:    true,
+			fullReport:   true,
+			wantHeadings: parseMarkdownReport(t, *full).headings,
+			wantTables:   parseMarkdownReport(t, *full).tables,
+			wantDetails:  []string{"vulnerable @ 1.0.0", "unreachable @ 1.0.0", "lib @ 2.0.0"},
+		},
+		{
+			// The dependency violating the policy is kept, although lib has a lower score than it.
+			name:      "truncated",
+			maxBytes:  1500,
+			truncated: true,
+			wantHeadings: append(
+				append([]string{":sparkles: Added dependencies"}, evaluationHeadings...),
+				"4 more dependency changes omitted",
+			),
+			wantTables: map[string][][]string{
+				":sparkles: Added dependencies": addedTable("vulnerable"),
+				"4 more dependency changes omitted": {
+					{"Ecosystem", "Added", "Updated", "Removed"},
+					{"Go", "0", "1", "0"},
+					{"npm", "2", "0", "1"},
+				},
+			},
+			wantDetails: []string{"vulnerable @ 1.0.0"},
+		},
+		{
+			// The policy evaluation is kept over the changes.
+			name:         "policy evaluation only",
+			maxBytes:     1000,
+			truncated:    true,
+			wantHeadings: append(append([]string{}, evaluationHeadings...), "5 more dependency changes omitted"),
+			wantTables: map[string][][]string{
+				"5 more dependency changes omitted": {
+					{"Ecosystem", "Added", "Updated", "Removed"},
+					{"Go", "0", "1", "0"},
+					{"npm", "3", "0", "1"},
+				},
+			},
+			wantDetails: []string{},
+		},
+		{
+			name:         "verdict and counts",
+			maxBytes:     300,
+			truncated:    true,
+			wantHeadings: []string{"Policy verdict: `fail`", "5 more dependency changes omitted"},
+			wantTables: map[string][][]string{
+				"5 more dependency changes omitted": {
+					{"Ecosystem", "Added", "Updated", "Removed"},
+					{"Go", "0", "1", "0"},
+					{"npm", "3", "0", "1"},
+				},
+			},
+			wantDetails: []string{},
+		},
+		{
+			name:         "verdict only",
+			maxBytes:     200,
+			truncated:    true,
+			wantHeadings: []string{"Policy verdict: `fail`"},
+			wantTables:   map[string][][]string{},
+			wantDetails:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fullReportFile := filepath.Join(t.TempDir(), "report.md")
+			limit := markdownLimit{maxBytes: tt.maxBytes}
+			if tt.fullReport {
+				limit.fullReportFile = fullReportFile
+			}
+			report, err := SprintDependencyChecksToMarkdown(results, log.DefaultLevel, checkDocs, policy, limit)
+			if err != nil {
+				t.Fatalf("SprintDependencyChecksToMarkdown: %v", err)
+			}
+			if len(*report) > tt.maxBytes {
+				t.Errorf("got %d bytes, want at most %d", len(*report), tt.maxBytes)
+			}
+			got := parseMarkdownReport(t, *report)
+			if !reflect.DeepEqual(got.headings, tt.wantHeadings) {
+				t.Errorf("got headings %q, want %q", got.headings, tt.wantHeadings)
+			}
+			if !reflect.DeepEqual(got.tables, tt.wantTables) {
+				t.Errorf("got tables\n%q\nwant\n%q", got.tables, tt.wantTables)
+			}
+			details := []string{}
+			for summary := range got.details {
+				details = append(details, summary)
+			}
+			sort.Strings(details)
+			sort.Strings(tt.wantDetails)
+			if !reflect.DeepEqual(details, tt.wantDetails) {
+				t.Errorf("got details of %q, want %q", details, tt.wantDetails)
+			}
+			wantNote := fmt.Sprintf(
+				"> :warning: The report exceeds %d bytes, only the dependencies most at risk are listed.\n",
+				tt.maxBytes,
+			)
+			written, err := os.ReadFile(fullReportFile)
+			switch {
+			case !tt.fullReport || !tt.truncated:
+				if !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("got full report file written, error %v", err)
+				}
+			case err != nil:
+				t.Errorf("os.ReadFile: %v", err)
+			case string(written) != *full:
+				t.Errorf("got full report\n%s\nwant\n%s", written, *full)
+			default:
+				wantNote += fmt.Sprintf("> The full report is available in `%s`.\n", fullReportFile)
+			}
+			wantNote += "\n"
+			if strings.HasPrefix(*report, wantNote) != tt.truncated {
+				t.Errorf("got report starting with %q, want the note %v", *report, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestSprintDependencyChecksToMarkdown_LimitBelowNote(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	policy := &pkg.DependencydiffPolicy{FailOnSeverity: pkg.High}
+	limit := markdownLimit{maxBytes: 20}
+	report, err := SprintDependencyChecksToMarkdown(testResults(), log.DefaultLevel, checkDocs, policy, limit)
+	if err != nil {
+		t.Fatalf("SprintDependencyChecksToMarkdown: %v", err)
+	}
+	// The note is cut at the limit.
+	if want := "> :warning: The repo"; *report != want {
+		t.Errorf("got report %q, want %q", *report, want)
+	}
+}
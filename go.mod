@@ -8,6 +8,7 @@ require (
 	github.com/google/go-github/v38 v38.1.0
 	github.com/ossf/scorecard/v4 v4.4.0
 	github.com/spf13/cobra v1.4.0
+	gopkg.in/yaml.v3 v3.0.0-20220512140231-539c8e751b99
 )
 
 require (
@@ -63,7 +64,6 @@ require (
 	google.golang.org/grpc v1.47.0 // indirect
 	google.golang.org/protobuf v1.28.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.0-20220512140231-539c8e751b99 // indirect
 	mvdan.cc/sh/v3 v3.5.1 // indirect
 	sigs.k8s.io/release-utils v0.6.0 // indirect
 )
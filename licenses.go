@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+)
+
+// depsDevURL is the base URL of the deps.dev API.
+const depsDevURL = "https://api.deps.dev/v3"
+
+// osvToDepsDevSystem maps the OSV ecosystems to the package management systems of deps.dev.
+var osvToDepsDevSystem = map[ecosystem]string{
+	ecosystemGo:       "GO",
+	ecosystemNpm:      "NPM",
+	ecosystemCrates:   "CARGO",
+	ecosystemPyPI:     "PYPI",
+	ecosystemMaven:    "MAVEN",
+	ecosystemRubyGems: "RUBYGEMS",
+	ecosystemNuGet:    "NUGET",
+}
+
+// depsDevVersion is a package version as returned by the deps.dev API:
+// https://docs.deps.dev/api/v3/#getversion.
+type depsDevVersion struct {
+	// Licenses are SPDX expressions, or "non-standard" for the licenses which cannot be identified.
+	Licenses []string `json:"licenses"`
+}
+
+// registryLicenses is a source of the declared licenses of package versions in the metadata of their registries,
+// as collected by deps.dev, for the dependencies of which the Dependency Review API reports no license. Every
+// package version is fetched once.
+type registryLicenses struct {
+	ctx      context.Context
+	client   *http.Client
+	baseURL  string
+	licenses map[string]string
+}
+
+func newRegistryLicenses(ctx context.Context, client *http.Client) *registryLicenses {
+	return &registryLicenses{ctx: ctx, client: client, baseURL: depsDevURL, licenses: map[string]string{}}
+}
+
+// fill sets the unknown licenses of the results to those of the registries, which stay unknown if the registries
+// don't know them either.
+func (r *registryLicenses) fill(results []pkg.DependencyCheckResult) error {
+	for i := range results {
+		d := &results[i]
+		if d.Ecosystem == nil || d.Version == nil || *d.Version == "" || !isUnknownLicense(d.License) {
+			continue
+		}
+		system, found := osvToDepsDevSystem[ecosystem(*d.Ecosystem)]
+		if !found {
+			continue
+		}
+		license, err := r.license(system, d.Name, *d.Version)
+		if err != nil {
+			return err
+		}
+		if license != "" {
+			d.License = asPointer(license)
+		}
+	}
+	return nil
+}
+
+// isUnknownLicense checks whether a declared license is missing or unknown, as the NOASSERTION and NONE values
+// of SPDX.
+func isUnknownLicense(license *string) bool {
+	if license == nil {
+		return true
+	}
+	switch strings.ToUpper(strings.TrimSpace(*license)) {
+	case "", "NOASSERTION", "NONE":
+		return true
+	default:
+		return false
+	}
+}
+
+// license fetches the license of a package version, which is empty if the version is not found or any of its
+// licenses is not identified. Several licenses all apply, so they are joined by the AND operator.
+func (r *registryLicenses) license(system, name, version string) (string, error) {
+	key := system + "|" + name + "|" + version
+	if license, found := r.licenses[key]; found {
+		return license, nil
+	}
+	endpoint := fmt.Sprintf("%s/systems/%s/packages/%s/versions/%s",
+		r.baseURL, system, url.PathEscape(name), url.PathEscape(version))
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("request for the license of %s@%s failed with %w", name, version, err)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error fetching the license of %s@%s: %w", name, version, err)
+	}
+	defer resp.Body.Close()
+	v := &depsDevVersion{}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return "", fmt.Errorf("error decoding the license of %s@%s: %w", name, version, err)
+		}
+	case http.StatusNotFound:
+	default:
+		return "", fmt.Errorf("%w %s fetching the license of %s@%s", errUnexpectedStatus, resp.Status, name, version)
+	}
+	license := joinLicenses(v.Licenses)
+	r.licenses[key] = license
+	return license, nil
+}
+
+// joinLicenses joins licenses by the AND operator, or returns an empty string if any of them is not an SPDX
+// expression.
+func joinLicenses(licenses []string) string {
+	operands := []string{}
+	for _, l := range licenses {
+		if l == "" || strings.EqualFold(l, "non-standard") {
+			return ""
+		}
+		operands = append(operands, "("+l+")")
+	}
+	if len(operands) == 1 {
+		return licenses[0]
+	}
+	return strings.Join(operands, " AND ")
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+)
+
+// fakeDepsDev is a fake of the deps.dev API, serving the licenses of a few package versions.
+type fakeDepsDev struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (f *fakeDepsDev) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	responses := map[string]string{
+		"/systems/NPM/packages/lodash/versions/4.17.21":                  `{"licenses": ["MIT"]}`,
+		"/systems/GO/packages/github.com%2Fowner%2Flib/versions/v1.0.0":  `{"licenses": ["MIT", "Apache-2.0 OR 0BSD"]}`,
+		"/systems/MAVEN/packages/org.example:lib/versions/1.0.0":         `{"licenses": ["non-standard"]}`,
+		"/systems/PYPI/packages/unavailable/versions/1.0.0":              "",
+		"/systems/CARGO/packages/serde/versions/1.0.0":                   `{"licenses": []}`,
+		"/systems/NPM/packages/@scope%2Fpkg/versions/1.0.0":              `{"licenses": ["ISC"]}`,
+		"/systems/GO/packages/github.com%2Fowner%2Fnone/versions/v2.0.0": `{"licenses": ["BSD-2-Clause"]}`,
+	}
+	response, found := responses[r.URL.EscapedPath()]
+	switch {
+	case r.Method != http.MethodGet || !found:
+		w.WriteHeader(http.StatusNotFound)
+	case response == "":
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		w.Write([]byte(response)) //nolint:errcheck
+	}
+}
+
+func TestRegistryLicenses_Fill(t *testing.T) {
+	t.Parallel()
+	fake := &fakeDepsDev{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	licenses := newRegistryLicenses(context.Background(), server.Client())
+	licenses.baseURL = server.URL
+
+	result := func(eco, name, version string, license *string) pkg.DependencyCheckResult {
+		return pkg.DependencyCheckResult{Ecosystem: &eco, Name: name, Version: &version, License: license}
+	}
+	declared, noAssertion := "Apache-2.0", "NOASSERTION"
+	results := []pkg.DependencyCheckResult{
+		result("npm", "lodash", "4.17.21", nil),
+		result("Go", "github.com/owner/lib", "v1.0.0", nil),
+		result("Maven", "org.example:lib", "1.0.0", nil),
+		result("crates.io", "serde", "1.0.0", nil),
+		result("npm", "@scope/pkg", "1.0.0", nil),
+		result("npm", "unknown", "1.0.0", nil),
+		// The declared licenses are kept, and the registries are not asked for them.
+		result("npm", "declared", "1.0.0", &declared),
+		result("Go", "github.com/owner/none", "v2.0.0", &noAssertion),
+		// deps.dev does not support Packagist.
+		result("Packagist", "vendor/pkg", "1.0.0", nil),
+		// A package version is fetched once.
+		result("npm", "lodash", "4.17.21", nil),
+	}
+	if err := licenses.fill(results); err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+	want := []string{
+		"MIT", "(MIT) AND (Apache-2.0 OR 0BSD)", "", "", "ISC", "", "Apache-2.0", "BSD-2-Clause", "", "MIT",
+	}
+	for i, r := range results {
+		got := ""
+		if r.License != nil {
+			got = *r.License
+		}
+		if got != want[i] {
+			t.Errorf("license of %s = %q, want %q", r.Name, got, want[i])
+		}
+	}
+	if fake.requests != 7 {
+		t.Errorf("got %d requests, want 7", fake.requests)
+	}
+
+	// The errors of the API other than a missing version fail.
+	if err := licenses.fill([]pkg.DependencyCheckResult{result("PyPI", "unavailable", "1.0.0", nil)}); err == nil {
+		t.Errorf("fill: got no error, want an error for an unavailable API")
+	}
+}
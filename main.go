@@ -2,59 +2,330 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/aidenwang9867/depdiffvis/options"
+	"github.com/aidenwang9867/depdiffvis/osv"
 	"github.com/aidenwang9867/depdiffvis/pkg"
 	"github.com/ossf/scorecard/v4/checks"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	"github.com/ossf/scorecard/v4/log"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// envVarOSVDatabase is the environment variable which points to an optional offline OSV database,
+	// used to find vulnerabilities of dependencies in addition to those reported by GitHub, and their fixes.
+	envVarOSVDatabase = "DEPDIFF_OSV_DATABASE"
+
+	// envVarFullReportFile is the environment variable which points to an optional file to write the full
+	// markdown report to, when the printed report is truncated to fit in a PR comment.
+	envVarFullReportFile = "DEPDIFF_FULL_REPORT_FILE"
+
+	// envVarPullRequest is the environment variable which holds an optional pull request number, to publish the
+	// markdown report as a comment of the pull request.
+	envVarPullRequest = "DEPDIFF_PULL_REQUEST"
+
+	// envVarCheckRun is the environment variable which enables publishing the policy evaluation as a check run
+	// of the HEAD commit, which must then be given as a SHA.
+	envVarCheckRun = "DEPDIFF_CHECK_RUN"
+
+	// maxCommentBytes is the maximum size of a GitHub comment.
+	maxCommentBytes = 65536
+)
+
+// formats are the output formats supported by dependency-diff.
+var formats = []string{
+	options.FormatDefault, options.FormatMarkdown, options.FormatJSON, options.FormatSarif, options.FormatHTML,
+	options.FormatJUnit, options.FormatCSV, options.FormatTSV, options.FormatTable, options.FormatDOT,
+	options.FormatMermaid, options.FormatTemplate,
+}
+
+// Exit codes of dependency-diff.
+const (
+	// exitCodePolicyFailure is the exit code when the dependency changes fail the policy.
+	exitCodePolicyFailure = 1
+	// exitCodeError is the exit code when dependency-diff cannot run, e.g. because of invalid options.
+	exitCodeError = 2
 )
 
 func main() {
-	// Args[0] is the program path, so use args from Args[1].
-	// Args should include:
-	// (0) owner name, (1) repo name,
-	// (2) base commit SHA, (3) head commit SHA.
-	args := os.Args[1:]
-	if len(args) != 3 {
-		fmt.Println("len of args not equals to 3")
-		return
-	}
-	repoURI, base, head := args[0], args[1], args[2]
-	//Fetch dependency diffs using the GitHub Dependency Review API.
-	checksToRun := []string{
-		// checks.CheckCodeReview,
-		// checks.CheckSAST,
-		// checks.CheckBranchProtection,
-		checks.CheckLicense,
-	}
-	changeTypeToCheck := map[pkg.ChangeType]bool{
-		pkg.Added:   true,
-		pkg.Updated: true,
-		// pkg.Removed: true,
-	}
-	results, err := GetDependencyDiffResults(
-		context.Background(), repoURI, base, head, checksToRun, changeTypeToCheck,
-	)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	// fmt.Println(results)
-	markdown, err := SprintDependencyChecksToMarkdown(results)
-	// if err != nil {
-	// 	fmt.Println(err)
-	// 	return
-	// }
-	// title := "# [Scorecards' Github action](https://github.com/ossf/scorecard-action) Dependency-diff Report\n\n"
-	// title += fmt.Sprintf(
-	// 	"Dependency-diffs (changes) between the BASE commit `%s` and the HEAD commit `%s`:\n\n",
-	// 	args[2], args[3],
-	// )
-	// fmt.Print(title)
-	if *markdown == "" {
-		fmt.Println("No dependency changes found.")
+	if err := newDepdiffCommand(options.New()).Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if errors.Is(err, errPolicyFailure) {
+			os.Exit(exitCodePolicyFailure)
+		}
+		os.Exit(exitCodeError)
+	}
+}
+
+// newDepdiffCommand creates the depdiff command, which runs the checks on the dependency changes of a repo
+// between two commits.
+func newDepdiffCommand(opts *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "depdiff --repo owner/repo --commit base...head",
+		Short: "Run Scorecard checks on the dependency changes between two commits",
+		Args:  cobra.NoArgs,
+		// Errors are printed by main, and only those of the flags are usage errors.
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := resolveOptions(cmd, opts); err != nil {
+				return err
+			}
+			if !isSupportedFormat(opts.Format) {
+				return fmt.Errorf("%w: format %q, possible values are: %s",
+					errInvalid, opts.Format, strings.Join(formats, ", "))
+			}
+			return opts.ValidateDepdiff()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDepdiff(cmd.Context(), opts)
+		},
+	}
+	opts.AddDepdiffFlags(cmd, formats)
+	cmd.AddCommand(newConfigCommand(opts))
+	return cmd
+}
+
+// runDepdiff gets the dependency changes and their check results, writes and publishes them, and returns
+// errPolicyFailure if they fail the policy.
+func runDepdiff(ctx context.Context, opts *options.Options) error {
+	repoURI, base, head := repoURIOf(opts.Repo), opts.Base, opts.Head
+	checksToRun, err := checksToRunOf(opts.ChecksToRun)
+	if err != nil {
+		return err
+	}
+	changeTypeToCheck, err := changeTypesToCheckOf(opts.ChangeTypes)
+	if err != nil {
+		return err
+	}
+	logLevel := log.ParseLevel(opts.LogLevel)
+	policy, err := pkg.ReadDependencydiffPolicy(opts.DepdiffPolicyFile)
+	if err != nil {
+		return err
+	}
+	// The weights of the config override those of the policy.
+	if len(opts.Weights) != 0 {
+		if policy == nil {
+			policy = &pkg.DependencydiffPolicy{}
+		}
+		if policy.Weights == nil {
+			policy.Weights = map[string]float64{}
+		}
+		for check, weight := range opts.Weights {
+			policy.Weights[check] = weight
+		}
+	}
+	// Fetch dependency diffs using the GitHub Dependency Review API.
+	var results []pkg.DependencyCheckResult
+	if opts.DryRun {
+		results, err = GetDependencyDiffs(ctx, repoURI, base, head, changeTypeToCheck)
 	} else {
-		fmt.Println(*markdown)
+		results, err = GetDependencyDiffResults(
+			ctx, repoURI, base, head, checksToRun, changeTypeToCheck, opts.Workers, opts.CacheDir,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	// A dry run makes no calls besides fetching the dependency diffs, so the vulnerabilities are only those
+	// reported by the Dependency Review API, without fix suggestions, and the licenses are only those it declares.
+	if !opts.DryRun {
+		if err := matchVulnerabilities(ctx, results, logLevel); err != nil {
+			return err
+		}
+		if err := newRegistryLicenses(ctx, http.DefaultClient).fill(results); err != nil {
+			return err
+		}
+	}
+	checkDocs, err := docs.Read()
+	if err != nil {
+		return fmt.Errorf("error reading the check docs: %w", err)
+	}
+	if err := writeResults(opts, results, checkDocs, policy); err != nil {
+		return err
+	}
+	// A dry run is not published, as the checks did not run.
+	if pullRequest := os.Getenv(envVarPullRequest); pullRequest != "" && !opts.DryRun {
+		if err := publishResults(
+			ctx, repoURI, pullRequest, logLevel, results, checkDocs, policy, os.Getenv(envVarFullReportFile),
+		); err != nil {
+			return err
+		}
+	}
+	evaluation, err := pkg.EvaluateDependencydiffPolicy(results, policy, checkDocs)
+	if err != nil {
+		return err
+	}
+	if isGitHubActions() {
+		if err := writeActionResults(results, logLevel, checkDocs, policy, evaluation); err != nil {
+			return err
+		}
+	}
+	if checkRun, _ := strconv.ParseBool(os.Getenv(envVarCheckRun)); checkRun && !opts.DryRun {
+		if err := publishCheckRunResults(
+			ctx, repoURI, head, logLevel, results, checkDocs, policy, evaluation,
+		); err != nil {
+			return err
+		}
+	}
+	return verdictError(evaluation, opts.DryRun)
+}
+
+// verdictError returns errPolicyFailure if the policy is violated, e.g. a vulnerability at or above the severity
+// threshold is introduced, so that the run fails. A dry run never fails on the verdict, which is only reported
+// in the results and the outputs of the action, since the checks did not run.
+func verdictError(evaluation *pkg.PolicyEvaluation, dryRun bool) error {
+	if evaluation.Verdict != pkg.VerdictFail || dryRun {
+		return nil
+	}
+	return fmt.Errorf("%w: %d violations", errPolicyFailure, len(evaluation.Violations))
+}
+
+// repoURIOf trims the host of a repo given as "github.com/owner/repo" or "https://github.com/owner/repo" to
+// the "owner/repo" URI.
+func repoURIOf(repo string) string {
+	repo = strings.TrimPrefix(repo, "https://")
+	return strings.TrimPrefix(repo, "github.com/")
+}
+
+// checksToRunOf gets the checks to run from their case-insensitive names, which default to the License check.
+func checksToRunOf(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return []string{checks.CheckLicense}, nil
+	}
+	allChecks := checks.GetAll()
+	checksToRun := []string{}
+	for _, name := range names {
+		found := false
+		for checkName := range allChecks {
+			if strings.EqualFold(checkName, name) {
+				checksToRun = append(checksToRun, checkName)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: check %s", errInvalid, name)
+		}
+	}
+	return checksToRun, nil
+}
+
+// changeTypesToCheckOf gets the change types to check from their names, which default to added and updated.
+func changeTypesToCheckOf(names []string) (map[pkg.ChangeType]bool, error) {
+	if len(names) == 0 {
+		return map[pkg.ChangeType]bool{
+			pkg.Added:   true,
+			pkg.Updated: true,
+		}, nil
+	}
+	changeTypes := map[pkg.ChangeType]bool{}
+	for _, name := range names {
+		ct := pkg.ChangeType(strings.ToLower(name))
+		if !ct.IsValid() {
+			return nil, fmt.Errorf("%w: change type %s", errInvalid, name)
+		}
+		changeTypes[ct] = true
+	}
+	return changeTypes, nil
+}
+
+func isSupportedFormat(format string) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// matchVulnerabilities adds the vulnerabilities of the offline OSV database, if any, to the results, and suggests
+// fixed versions for the vulnerable dependencies. The offline OSV database is preferred over the GitHub Advisory
+// Database to find the fixed versions.
+func matchVulnerabilities(ctx context.Context, results []pkg.DependencyCheckResult, logLevel log.Level) error {
+	fixSources := []osv.FixSource{}
+	if path := os.Getenv(envVarOSVDatabase); path != "" {
+		db, err := osv.LoadDatabase(path)
+		if err != nil {
+			return err
+		}
+		db.Match(results)
+		fixSources = append(fixSources, db)
+	}
+	fixSources = append(fixSources, newAdvisoryFixes(ctx, newGitHubClient(ctx, logLevel)))
+	if err := osv.SuggestFixes(results, fixSources...); err != nil {
+		return fmt.Errorf("error suggesting fixes: %w", err)
+	}
+	return nil
+}
+
+// writeResults writes the results in the format given by the options to the results file, or to stdout if
+// no results file is given. The markdown report is printed to stdout unless the results already are, and is
+// truncated to fit in a PR comment, while a markdown results file gets the full report.
+func writeResults(
+	opts *options.Options, results []pkg.DependencyCheckResult, checkDocs docs.Doc, policy *pkg.DependencydiffPolicy,
+) error {
+	isMarkdown := opts.Format == options.FormatDefault || opts.Format == options.FormatMarkdown
+	switch {
+	case isMarkdown:
+	case opts.Format == options.FormatTable:
+		if err := writeTable(opts, results, checkDocs, policy); err != nil {
+			return err
+		}
+	default:
+		if err := pkg.FormatDependencydiffResults(opts, results, checkDocs, policy); err != nil {
+			return fmt.Errorf("error formatting the results: %w", err)
+		}
+	}
+	if !isMarkdown && opts.ResultsFile == "" {
+		return nil
+	}
+	logLevel := log.ParseLevel(opts.LogLevel)
+	if isMarkdown && opts.ResultsFile != "" {
+		markdown, err := SprintDependencyChecksToMarkdown(results, logLevel, checkDocs, policy, markdownLimit{})
+		if err != nil {
+			return fmt.Errorf("error formatting the results as markdown: %w", err)
+		}
+		if err := os.WriteFile(opts.ResultsFile, []byte(orNoChanges(*markdown)), 0o600); err != nil {
+			return fmt.Errorf("error writing the results file: %w", err)
+		}
+	}
+	markdown, err := SprintDependencyChecksToMarkdown(results, logLevel, checkDocs, policy, markdownLimit{
+		maxBytes:       maxCommentBytes,
+		fullReportFile: os.Getenv(envVarFullReportFile),
+	})
+	if err != nil {
+		return fmt.Errorf("error formatting the results as markdown: %w", err)
+	}
+	fmt.Println(orNoChanges(*markdown))
+	return nil
+}
+
+// writeTable prints the table to the results file, or to stdout in colors if it is a terminal.
+func writeTable(
+	opts *options.Options, results []pkg.DependencyCheckResult, checkDocs docs.Doc, policy *pkg.DependencydiffPolicy,
+) error {
+	if opts.ResultsFile == "" {
+		return printDependencyTable(os.Stdout, results, checkDocs, policy, isColorTerminal(os.Stdout))
+	}
+	f, err := os.Create(opts.ResultsFile)
+	if err != nil {
+		return fmt.Errorf("error creating the results file: %w", err)
+	}
+	defer f.Close()
+	return printDependencyTable(f, results, checkDocs, policy, false)
+}
+
+func orNoChanges(markdown string) string {
+	if markdown == "" {
+		return "No dependency changes found.\n"
 	}
-	// return
+	return markdown
 }
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aidenwang9867/depdiffvis/options"
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	"github.com/ossf/scorecard/v4/log"
+)
+
+func TestIsSupportedFormat(t *testing.T) {
+	t.Parallel()
+	//nolint
+	tests := []struct {
+		format string
+		want   bool
+	}{
+		{format: options.FormatDefault, want: true},
+		{format: options.FormatMarkdown, want: true},
+		{format: options.FormatJSON, want: true},
+		{format: options.FormatSarif, want: true},
+		{format: "yaml", want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.format, func(t *testing.T) {
+			t.Parallel()
+			if got := isSupportedFormat(tt.format); got != tt.want {
+				t.Errorf("isSupportedFormat(%q) = %v, want %v", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+//nolint:paralleltest
+func TestWriteResults(t *testing.T) {
+	// Cannot run parallel tests because the results are printed to stdout.
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	added, version := pkg.Added, "1.0.0"
+	results := []pkg.DependencyCheckResult{{Name: "lib", ChangeType: &added, Version: &version}}
+	markdown, err := SprintDependencyChecksToMarkdown(results, log.DefaultLevel, checkDocs, nil, markdownLimit{})
+	if err != nil {
+		t.Fatalf("SprintDependencyChecksToMarkdown: %v", err)
+	}
+	//nolint
+	tests := []struct {
+		name        string
+		format      string
+		resultsFile bool
+		// wantFile is the format of the results file, empty if no file is written.
+		wantFile string
+		// wantStdout is the format printed to stdout.
+		wantStdout string
+	}{
+		{
+			name:       "markdown to stdout",
+			format:     options.FormatMarkdown,
+			wantStdout: options.FormatMarkdown,
+		},
+		{
+			name:        "markdown to a results file",
+			format:      options.FormatDefault,
+			resultsFile: true,
+			wantFile:    options.FormatMarkdown,
+			wantStdout:  options.FormatMarkdown,
+		},
+		{
+			name:       "json to stdout",
+			format:     options.FormatJSON,
+			wantStdout: options.FormatJSON,
+		},
+		{
+			name:        "json to a results file",
+			format:      options.FormatJSON,
+			resultsFile: true,
+			wantFile:    options.FormatJSON,
+			wantStdout:  options.FormatMarkdown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &options.Options{Format: tt.format, LogLevel: options.DefaultLogLevel}
+			if tt.resultsFile {
+				opts.ResultsFile = filepath.Join(t.TempDir(), "results")
+			}
+			stdout := captureStdout(t, func() {
+				if err := writeResults(opts, results, checkDocs, nil); err != nil {
+					t.Fatalf("writeResults: %v", err)
+				}
+			})
+			checkOutput(t, "stdout", stdout, tt.wantStdout, *markdown)
+			if tt.wantFile == "" {
+				return
+			}
+			content, err := os.ReadFile(opts.ResultsFile)
+			if err != nil {
+				t.Fatalf("os.ReadFile: %v", err)
+			}
+			checkOutput(t, "results file", string(content), tt.wantFile, *markdown)
+		})
+	}
+}
+
+// checkOutput checks that the output is the markdown report or a JSON result of the given format.
+func checkOutput(t *testing.T, name, output, format, markdown string) {
+	t.Helper()
+	switch format {
+	case options.FormatMarkdown:
+		if strings.TrimSpace(output) != strings.TrimSpace(markdown) {
+			t.Errorf("got %s %q, want the markdown report %q", name, output, markdown)
+		}
+	case options.FormatJSON:
+		var got []pkg.JSONDependencydiffResult
+		if err := json.Unmarshal([]byte(output), &got); err != nil {
+			t.Errorf("got %s %q, want JSON results: %v", name, output, err)
+		}
+	}
+}
+
+// captureStdout returns what f prints to stdout.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+	// Read concurrently so that a large output does not fill the pipe.
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	stdout := os.Stdout
+	os.Stdout = w
+	f()
+	os.Stdout = stdout
+	w.Close()
+	return <-out
+}
+
+func TestVerdictError(t *testing.T) {
+	t.Parallel()
+	failed := &pkg.PolicyEvaluation{
+		Verdict:    pkg.VerdictFail,
+		Violations: []pkg.PolicyViolation{{Verdict: pkg.VerdictFail}},
+	}
+	//nolint
+	tests := []struct {
+		name       string
+		evaluation *pkg.PolicyEvaluation
+		dryRun     bool
+		wantErr    error
+	}{
+		{name: "pass", evaluation: &pkg.PolicyEvaluation{Verdict: pkg.VerdictPass}},
+		{name: "warn", evaluation: &pkg.PolicyEvaluation{Verdict: pkg.VerdictWarn}},
+		{name: "fail", evaluation: failed, wantErr: errPolicyFailure},
+		{name: "fail in a dry run", evaluation: failed, dryRun: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := verdictError(tt.evaluation, tt.dryRun); !errors.Is(err, tt.wantErr) {
+				t.Errorf("verdictError() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package options
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the repository config file of dependency-diff, which is discovered from the
+// working directory up to the root of the repository.
+const ConfigFileName = ".depdiff.yml"
+
+// Config is the repository config file of dependency-diff. Its settings apply to the options which are
+// neither given by flags nor by environment variables, which include the inputs of the GitHub Action.
+type Config struct {
+	Repo         string             `yaml:"repo,omitempty"`
+	Base         string             `yaml:"base,omitempty"`
+	Head         string             `yaml:"head,omitempty"`
+	Checks       []string           `yaml:"checks,omitempty"`
+	ChangeTypes  []string           `yaml:"change_types,omitempty"`
+	Policy       string             `yaml:"policy,omitempty"`
+	Weights      map[string]float64 `yaml:"weights,omitempty"`
+	Format       string             `yaml:"format,omitempty"`
+	ResultsFile  string             `yaml:"results_file,omitempty"`
+	TemplateFile string             `yaml:"template,omitempty"`
+	Workers      int                `yaml:"workers,omitempty"`
+	CacheDir     string             `yaml:"cache_dir,omitempty"`
+	LogLevel     string             `yaml:"verbosity,omitempty"`
+	DryRun       bool               `yaml:"dry_run,omitempty"`
+}
+
+var errConfigFileInvalid = errors.New("invalid config file")
+
+// FindConfigFile finds the config file in a directory or its parents, stopping at the root of the repository,
+// which is the first directory containing .git. An empty path is returned if there is no config file.
+func FindConfigFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("filepath.Abs: %w", err)
+	}
+	for {
+		path := filepath.Join(dir, ConfigFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("os.Stat: %w", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// ReadConfig reads a config file. Unknown settings are rejected, so that misspelled ones are not ignored.
+// The relative paths of the policy and template files are relative to the directory of the config file.
+func ReadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	config := Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	// An empty config file has no settings.
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w %s: %v", errConfigFileInvalid, path, err)
+	}
+	for _, p := range []*string{&config.Policy, &config.TemplateFile} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(filepath.Dir(path), *p)
+		}
+	}
+	return &config, nil
+}
+
+// ApplyConfig sets the options to the settings of a config, unless they are given by flags, as reported by
+// flagChanged, or by environment variables. Flags thus take precedence over environment variables, which take
+// precedence over the config, which takes precedence over the defaults.
+func (o *Options) ApplyConfig(c *Config, flagChanged func(name string) bool) {
+	isSet := func(flag, envVar string) bool {
+		_, found := os.LookupEnv(envVar)
+		return (flag != "" && flagChanged(flag)) || (envVar != "" && found)
+	}
+	for _, s := range []struct {
+		flag, envVar string
+		set          bool
+		apply        func()
+	}{
+		{FlagRepo, "", c.Repo != "", func() { o.Repo = c.Repo }},
+		// Base and head are given by the commit flag.
+		{FlagCommit, EnvVarDepdiffBase, c.Base != "", func() { o.Base = c.Base }},
+		{FlagCommit, EnvVarDepdiffHead, c.Head != "", func() { o.Head = c.Head }},
+		{FlagChecks, "", len(c.Checks) != 0, func() { o.ChecksToRun = c.Checks }},
+		{FlagChangeTypes, EnvVarDepdiffChangeTypes, len(c.ChangeTypes) != 0, func() {
+			o.ChangeTypes = c.ChangeTypes
+		}},
+		{FlagDepdiffPolicyFile, EnvVarDepdiffPolicyFile, c.Policy != "", func() {
+			o.DepdiffPolicyFile = c.Policy
+		}},
+		{"", "", len(c.Weights) != 0, func() { o.Weights = c.Weights }},
+		{FlagFormat, "", c.Format != "", func() { o.Format = c.Format }},
+		{FlagResultsFile, EnvVarDepdiffResultsFile, c.ResultsFile != "", func() {
+			o.ResultsFile = c.ResultsFile
+		}},
+		{FlagTemplateFile, "", c.TemplateFile != "", func() { o.TemplateFile = c.TemplateFile }},
+		{"", EnvVarDepdiffWorkers, c.Workers != 0, func() { o.Workers = c.Workers }},
+		{"", EnvVarDepdiffCacheDir, c.CacheDir != "", func() { o.CacheDir = c.CacheDir }},
+		{FlagLogLevel, "", c.LogLevel != "", func() { o.LogLevel = c.LogLevel }},
+		{FlagDryRun, EnvVarDepdiffDryRun, c.DryRun, func() { o.DryRun = c.DryRun }},
+	} {
+		if s.set && !isSet(s.flag, s.envVar) {
+			s.apply()
+		}
+	}
+}
+
+// DepdiffConfig returns the effective dependency-diff configuration of the options.
+func (o *Options) DepdiffConfig() *Config {
+	return &Config{
+		Repo:         o.Repo,
+		Base:         o.Base,
+		Head:         o.Head,
+		Checks:       o.ChecksToRun,
+		ChangeTypes:  o.ChangeTypes,
+		Policy:       o.DepdiffPolicyFile,
+		Weights:      o.Weights,
+		Format:       o.Format,
+		ResultsFile:  o.ResultsFile,
+		TemplateFile: o.TemplateFile,
+		Workers:      o.Workers,
+		CacheDir:     o.CacheDir,
+		LogLevel:     o.LogLevel,
+		DryRun:       o.DryRun,
+	}
+}
@@ -0,0 +1,209 @@
+package options
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindConfigFile(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	sub := filepath.Join(repo, "sub", "dir")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("os.MkdirAll: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatalf("os.Mkdir: %v", err)
+	}
+	// The config file outside of the repository is not found.
+	if err := os.WriteFile(filepath.Join(root, ConfigFileName), nil, 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	got, err := FindConfigFile(sub)
+	if err != nil {
+		t.Fatalf("FindConfigFile: %v", err)
+	}
+	if got != "" {
+		t.Errorf("FindConfigFile() = %s, want no config file", got)
+	}
+	want := filepath.Join(repo, ConfigFileName)
+	if err := os.WriteFile(want, nil, 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	got, err = FindConfigFile(sub)
+	if err != nil {
+		t.Fatalf("FindConfigFile: %v", err)
+	}
+	if got != want {
+		t.Errorf("FindConfigFile() = %s, want %s", got, want)
+	}
+}
+
+func TestReadConfig(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	//nolint
+	tests := []struct {
+		name    string
+		content string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			name:    "empty",
+			content: "",
+			want:    &Config{},
+		},
+		{
+			name: "settings",
+			content: "checks: [License]\nchange_types: [added, updated]\npolicy: policy.yml\n" +
+				"template: /templates/report.tmpl\nweights:\n  License: 2\nworkers: 4\n",
+			want: &Config{
+				Checks:       []string{"License"},
+				ChangeTypes:  []string{"added", "updated"},
+				Policy:       filepath.Join(dir, "policy.yml"),
+				TemplateFile: "/templates/report.tmpl",
+				Weights:      map[string]float64{"License": 2},
+				Workers:      4,
+			},
+		},
+		{
+			name:    "unknown setting",
+			content: "change-types: [added]\n",
+			wantErr: true,
+		},
+	}
+	for i, tt := range tests {
+		i, tt := i, tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(dir, fmt.Sprintf("config%d.yml", i))
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("os.WriteFile: %v", err)
+			}
+			got, err := ReadConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+//nolint:paralleltest
+func TestOptions_ApplyConfig(t *testing.T) {
+	// Cannot run parallel tests because of the ENV variables.
+	config := &Config{
+		Repo:         "ossf/scorecard",
+		Base:         "main",
+		Head:         "dev",
+		Checks:       []string{"License"},
+		ChangeTypes:  []string{"added"},
+		Policy:       "/repo/policy.yml",
+		Weights:      map[string]float64{"License": 2},
+		Format:       "json",
+		ResultsFile:  "results.json",
+		TemplateFile: "/repo/report.tmpl",
+		Workers:      4,
+		CacheDir:     "/cache",
+		LogLevel:     "debug",
+		DryRun:       true,
+	}
+	fromConfig := Options{
+		Repo:              "ossf/scorecard",
+		Base:              "main",
+		Head:              "dev",
+		ChecksToRun:       []string{"License"},
+		ChangeTypes:       []string{"added"},
+		DepdiffPolicyFile: "/repo/policy.yml",
+		Weights:           map[string]float64{"License": 2},
+		Format:            "json",
+		ResultsFile:       "results.json",
+		TemplateFile:      "/repo/report.tmpl",
+		Workers:           4,
+		CacheDir:          "/cache",
+		LogLevel:          "debug",
+		DryRun:            true,
+	}
+	defaults := Options{Format: FormatDefault, Workers: DefaultWorkers, LogLevel: DefaultLogLevel}
+	//nolint
+	tests := []struct {
+		name    string
+		config  *Config
+		opts    Options
+		flags   []string
+		envVars map[string]string
+		want    func(o *Options)
+	}{
+		{
+			name:   "config applies to the defaults",
+			config: config,
+			opts:   defaults,
+			want:   func(o *Options) { *o = fromConfig },
+		},
+		{
+			name:   "empty config keeps the defaults",
+			config: &Config{},
+			opts:   defaults,
+			want:   func(o *Options) { *o = defaults },
+		},
+		{
+			name:   "flags take precedence",
+			config: config,
+			opts:   Options{Repo: "owner/repo", Format: "sarif", Workers: DefaultWorkers, LogLevel: "warn"},
+			flags:  []string{FlagRepo, FlagFormat, FlagLogLevel},
+			want: func(o *Options) {
+				*o = fromConfig
+				o.Repo, o.Format, o.LogLevel = "owner/repo", "sarif", "warn"
+			},
+		},
+		{
+			name:   "commit flag takes precedence over base and head",
+			config: config,
+			opts:   Options{Base: "v1", Head: "v2", Format: FormatDefault, Workers: DefaultWorkers},
+			flags:  []string{FlagCommit},
+			want: func(o *Options) {
+				*o = fromConfig
+				o.Base, o.Head = "v1", "v2"
+			},
+		},
+		{
+			name:   "environment variables take precedence",
+			config: config,
+			opts:   Options{Head: "env-head", Workers: 8, DryRun: false},
+			envVars: map[string]string{
+				EnvVarDepdiffHead:    "env-head",
+				EnvVarDepdiffWorkers: "8",
+				EnvVarDepdiffDryRun:  "false",
+			},
+			want: func(o *Options) {
+				*o = fromConfig
+				o.Head, o.Workers, o.DryRun = "env-head", 8, false
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.envVars {
+				t.Setenv(name, value)
+			}
+			changed := map[string]bool{}
+			for _, flag := range tt.flags {
+				changed[flag] = true
+			}
+			o := tt.opts
+			o.ApplyConfig(tt.config, func(name string) bool { return changed[name] })
+			want := &Options{}
+			tt.want(want)
+			if !reflect.DeepEqual(&o, want) {
+				t.Errorf("got %+v, want %+v", &o, want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package options
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -45,6 +46,24 @@ const (
 
 	// FlagFormat is the flag name for specifying output format.
 	FlagFormat = "format"
+
+	// FlagResultsFile is the flag name for specifying a file to write the results to.
+	FlagResultsFile = "results-file"
+
+	// FlagChangeTypes is the flag name for specifying the change types of the dependencies to check.
+	FlagChangeTypes = "change-types"
+
+	// FlagDepdiffPolicyFile is the flag name for specifying a dependency-diff policy file.
+	FlagDepdiffPolicyFile = "depdiff-policy"
+
+	// FlagDryRun is the flag name for listing the dependency changes without running the checks.
+	FlagDryRun = "dry-run"
+
+	// FlagConfigFile is the flag name for specifying a dependency-diff config file.
+	FlagConfigFile = "config"
+
+	// FlagTemplateFile is the flag name for specifying a template file to render the results with.
+	FlagTemplateFile = "template"
 )
 
 // Command is an interface for handling options for command-line utilities.
@@ -160,3 +179,96 @@ func (o *Options) AddRootFlags(cmd *cobra.Command) {
 		),
 	)
 }
+
+// AddDepdiffFlags adds the dependencydiff options' flags to the cobra command and its subcommands. The output
+// formats are given by the command, as they are implemented by it.
+func (o *Options) AddDepdiffFlags(cmd *cobra.Command, formats []string) {
+	cmd.PersistentFlags().StringVar(
+		&o.Repo,
+		FlagRepo,
+		o.Repo,
+		"repository to check the dependency changes of (valid input: \"owner/repo\")",
+	)
+
+	cmd.PersistentFlags().StringVar(
+		&o.Commit,
+		FlagCommit,
+		o.Commit,
+		`the two commits BASE and HEAD to diff the dependencies of, separated by "...". `+
+			`Both commitSHAs (commit_A_SHA...commit_B_SHA) or branch names ("main...dev") or a mix of them `+
+			`(main...commit_A_SHA) are supported. Defaults to the DEPDIFF_BASE and DEPDIFF_HEAD environment variables`,
+	)
+
+	checkNames := []string{}
+	for checkName := range checks.GetAll() {
+		checkNames = append(checkNames, checkName)
+	}
+	sort.Strings(checkNames)
+	cmd.PersistentFlags().StringSliceVar(
+		&o.ChecksToRun,
+		FlagChecks,
+		o.ChecksToRun,
+		fmt.Sprintf("Checks to run on the dependencies. Possible values are: %s", strings.Join(checkNames, ",")),
+	)
+
+	cmd.PersistentFlags().StringSliceVar(
+		&o.ChangeTypes,
+		FlagChangeTypes,
+		o.ChangeTypes,
+		"change types of the dependencies to run the checks on. Possible values are: added,updated,removed",
+	)
+
+	cmd.PersistentFlags().StringVar(
+		&o.Format,
+		FlagFormat,
+		o.Format,
+		fmt.Sprintf(
+			"output format. Possible values are: %s",
+			strings.Join(formats, ", "),
+		),
+	)
+
+	cmd.PersistentFlags().StringVar(
+		&o.ResultsFile,
+		FlagResultsFile,
+		o.ResultsFile,
+		"file to write the results to in the output format, the markdown report is still printed to stdout",
+	)
+
+	cmd.PersistentFlags().StringVar(
+		&o.DepdiffPolicyFile,
+		FlagDepdiffPolicyFile,
+		o.DepdiffPolicyFile,
+		"dependency-diff policy file to evaluate the dependency changes with",
+	)
+
+	cmd.PersistentFlags().BoolVar(
+		&o.DryRun,
+		FlagDryRun,
+		o.DryRun,
+		"list the dependency changes with the planned action of each dependency, without running the checks "+
+			"nor querying vulnerability databases and package registries",
+	)
+
+	cmd.PersistentFlags().StringVar(
+		&o.ConfigFile,
+		FlagConfigFile,
+		o.ConfigFile,
+		fmt.Sprintf("dependency-diff config file, defaults to the %s file found from the working directory",
+			ConfigFileName),
+	)
+
+	cmd.PersistentFlags().StringVar(
+		&o.TemplateFile,
+		FlagTemplateFile,
+		o.TemplateFile,
+		"text/template file to render the results with in the template format, or html/template if it ends with .html",
+	)
+
+	cmd.PersistentFlags().StringVar(
+		&o.LogLevel,
+		FlagLogLevel,
+		o.LogLevel,
+		"set the log level",
+	)
+}
@@ -4,9 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 
 	"github.com/caarlos0/env/v6"
 
+	"github.com/ossf/scorecard/v4/checks"
 	"github.com/ossf/scorecard/v4/clients"
 	"github.com/ossf/scorecard/v4/log"
 )
@@ -23,10 +26,33 @@ type Options struct {
 	RubyGems   string
 	PolicyFile string
 	// TODO(action): Add logic for writing results to file
-	ResultsFile string
-	ChecksToRun []string
-	Metadata    []string
-	ShowDetails bool
+	ResultsFile string `env:"DEPDIFF_RESULTS_FILE"`
+	// TemplateFile is the template file to render dependency-diff results with in the template format.
+	TemplateFile string
+	ChecksToRun  []string
+	Metadata     []string
+	ShowDetails  bool
+
+	// Dependency-diff options.
+
+	// Base and Head are the two commits to diff the dependencies of, which are also set by a "base...head"
+	// Commit.
+	Base string `env:"DEPDIFF_BASE"`
+	Head string `env:"DEPDIFF_HEAD"`
+	// ChangeTypes are the change types of the dependencies to run the checks on.
+	ChangeTypes []string `env:"DEPDIFF_CHANGE_TYPES" envSeparator:","`
+	// Workers is the number of dependencies to run the checks on concurrently.
+	Workers int `env:"DEPDIFF_WORKERS"`
+	// CacheDir is the directory to cache the check results of dependencies in, which expire after a day.
+	CacheDir string `env:"DEPDIFF_CACHE_DIR"`
+	// DepdiffPolicyFile is the dependency-diff policy file to evaluate the dependency changes with.
+	DepdiffPolicyFile string `env:"DEPDIFF_POLICY_FILE"`
+	// Weights overrides the weights of checks of the dependency-diff policy.
+	Weights map[string]float64
+	// DryRun lists the dependency changes without running the checks on the dependencies.
+	DryRun bool `env:"DEPDIFF_DRY_RUN"`
+	// ConfigFile is the dependency-diff config file, which is discovered from the working directory if empty.
+	ConfigFile string `env:"DEPDIFF_CONFIG_FILE"`
 
 	// Feature flags.
 	EnableSarif                 bool `env:"ENABLE_SARIF"`
@@ -53,6 +79,9 @@ func New() *Options {
 	if opts.LogLevel == "" {
 		opts.LogLevel = DefaultLogLevel
 	}
+	if opts.Workers == 0 {
+		opts.Workers = DefaultWorkers
+	}
 
 	return opts
 }
@@ -61,6 +90,9 @@ const (
 	// DefaultCommit specifies the default commit reference to use.
 	DefaultCommit = clients.HeadSHA
 
+	// DefaultWorkers specifies the default number of dependencies to run the checks on concurrently.
+	DefaultWorkers = 1
+
 	// Formats.
 
 	// FormatJSON specifies that results should be output in JSON format.
@@ -71,6 +103,25 @@ const (
 	FormatDefault = "default"
 	// FormatRaw specifies that results should be output in raw format.
 	FormatRaw = "raw"
+	// FormatMarkdown specifies that dependency-diff results should be output in markdown format,
+	// which is the default format of dependency-diff.
+	FormatMarkdown = "markdown"
+	// FormatTemplate specifies that dependency-diff results should be rendered with a user-supplied template.
+	FormatTemplate = "template"
+	// FormatHTML specifies that dependency-diff results should be output as a self-contained HTML report.
+	FormatHTML = "html"
+	// FormatJUnit specifies that dependency-diff results should be output as JUnit XML.
+	FormatJUnit = "junit"
+	// FormatCSV specifies that dependency-diff results should be output as comma-separated values.
+	FormatCSV = "csv"
+	// FormatTSV specifies that dependency-diff results should be output as tab-separated values.
+	FormatTSV = "tsv"
+	// FormatTable specifies that dependency-diff results should be output as a table for terminals.
+	FormatTable = "table"
+	// FormatDOT specifies that dependency-diff results should be output as a Graphviz DOT graph.
+	FormatDOT = "dot"
+	// FormatMermaid specifies that dependency-diff results should be output as a Mermaid flowchart.
+	FormatMermaid = "mermaid"
 
 	// Environment variables.
 
@@ -88,20 +139,63 @@ const (
 	EnvVarScorecardExperimental = "SCORECARD_EXPERIMENTAL"
 )
 
+// The dependency-diff environment variables are the env tags of the options, so that they are declared once.
+var (
+	// EnvVarDepdiffBase is the environment variable which sets the base commit of dependency-diff.
+	EnvVarDepdiffBase = envVarOf("Base")
+	// EnvVarDepdiffHead is the environment variable which sets the head commit of dependency-diff.
+	EnvVarDepdiffHead = envVarOf("Head")
+	// EnvVarDepdiffChangeTypes is the environment variable which sets the change types of dependency-diff.
+	EnvVarDepdiffChangeTypes = envVarOf("ChangeTypes")
+	// EnvVarDepdiffWorkers is the environment variable which sets the number of workers of dependency-diff.
+	EnvVarDepdiffWorkers = envVarOf("Workers")
+	// EnvVarDepdiffCacheDir is the environment variable which sets the cache directory of dependency-diff.
+	EnvVarDepdiffCacheDir = envVarOf("CacheDir")
+	// EnvVarDepdiffPolicyFile is the environment variable which sets the dependency-diff policy file.
+	EnvVarDepdiffPolicyFile = envVarOf("DepdiffPolicyFile")
+	// EnvVarDepdiffResultsFile is the environment variable which sets the results file of dependency-diff.
+	EnvVarDepdiffResultsFile = envVarOf("ResultsFile")
+	// EnvVarDepdiffDryRun is the environment variable which enables the dry run of dependency-diff.
+	EnvVarDepdiffDryRun = envVarOf("DryRun")
+)
+
+// envVarOf returns the environment variable of an option, given by the env tag of its field. It panics if the
+// field has no environment variable, which is a programming error.
+func envVarOf(field string) string {
+	f, found := reflect.TypeOf(Options{}).FieldByName(field)
+	if !found || f.Tag.Get("env") == "" {
+		panic(fmt.Sprintf("option %s has no environment variable", field))
+	}
+	return f.Tag.Get("env")
+}
+
 var (
 	// DefaultLogLevel retrieves the default log level.
 	DefaultLogLevel = log.DefaultLevel.String()
 
+	// DepdiffChangeTypes are the valid dependency-diff change types. They mirror pkg.ChangeType, which cannot be
+	// imported here as pkg imports this package.
+	DepdiffChangeTypes = []string{"added", "updated", "removed"}
+
+	errBaseIsEmpty              = errors.New("base commit should be non-empty")
+	errChangeTypeNotSupported   = errors.New("unsupported change type")
+	errCheckNotSupported        = errors.New("unsupported check")
 	errCommitIsEmpty            = errors.New("commit should be non-empty")
+	errCommitRangeInvalid       = errors.New(`commit should be two commits separated by "..."`)
+	errHeadIsEmpty              = errors.New("head commit should be non-empty")
 	errCommitOptionNotSupported = errors.New("commit option is not supported yet")
 	errFormatNotSupported       = errors.New("unsupported format")
 	errPolicyFileNotSupported   = errors.New("policy file is not supported yet")
 	errRawOptionNotSupported    = errors.New("raw option is not supported yet")
+	errRepoIsEmpty              = errors.New("repo should be non-empty")
 	errRepoOptionMustBeSet      = errors.New(
 		"exactly one of `repo`, `npm`, `pypi`, `rubygems` or `local` must be set",
 	)
 	errSARIFNotSupported    = errors.New("SARIF format is not supported yet")
+	errTemplateFileIsEmpty  = errors.New("template file should be non-empty with the template format")
 	errValidate             = errors.New("some options could not be validated")
+	errWeightNegative       = errors.New("check weight should be non-negative")
+	errWorkersNotPositive   = errors.New("workers should be positive")
 	errExperimentalDisabled = errors.New("scorecard experimental features are disabled")
 )
 
@@ -244,5 +338,124 @@ func (o *Options) ValidateDepdiff() error {
 			errExperimentalDisabled,
 		)
 	}
+
+	var errs []error
+
+	// Validate `repo` is non-empty.
+	if o.Repo == "" {
+		errs = append(
+			errs,
+			errRepoIsEmpty,
+		)
+	}
+
+	// Validate `base` and `head` are non-empty.
+	if o.Base == "" {
+		errs = append(
+			errs,
+			errBaseIsEmpty,
+		)
+	}
+	if o.Head == "" {
+		errs = append(
+			errs,
+			errHeadIsEmpty,
+		)
+	}
+
+	// Validate the change types and checks are known.
+	for _, ct := range o.ChangeTypes {
+		if !containsFold(DepdiffChangeTypes, ct) {
+			errs = append(
+				errs,
+				fmt.Errorf("%w: %s", errChangeTypeNotSupported, ct),
+			)
+		}
+	}
+	checkNames := []string{}
+	for checkName := range checks.GetAll() {
+		checkNames = append(checkNames, checkName)
+	}
+	for _, c := range o.ChecksToRun {
+		if !containsFold(checkNames, c) {
+			errs = append(
+				errs,
+				fmt.Errorf("%w: %s", errCheckNotSupported, c),
+			)
+		}
+	}
+
+	// Validate the weights are of known checks, whose names are case-sensitive as in policies, and non-negative.
+	allChecks := checks.GetAll()
+	for c, weight := range o.Weights {
+		if _, exists := allChecks[c]; !exists {
+			errs = append(
+				errs,
+				fmt.Errorf("%w: %s", errCheckNotSupported, c),
+			)
+		}
+		if weight < 0 {
+			errs = append(
+				errs,
+				fmt.Errorf("%w: %v for %s", errWeightNegative, weight, c),
+			)
+		}
+	}
+
+	// Validate `workers` is positive.
+	if o.Workers < 1 {
+		errs = append(
+			errs,
+			errWorkersNotPositive,
+		)
+	}
+
+	// Validate a template file is given to render the template format with.
+	if o.Format == FormatTemplate && o.TemplateFile == "" {
+		errs = append(
+			errs,
+			errTemplateFileIsEmpty,
+		)
+	}
+
+	if len(errs) != 0 {
+		return fmt.Errorf(
+			"%w: %+v",
+			errValidate,
+			errs,
+		)
+	}
+
 	return nil
 }
+
+// SetDepdiffCommits sets Base and Head from Commit, unless it is the default commit.
+func (o *Options) SetDepdiffCommits() error {
+	if o.Commit == DefaultCommit {
+		return nil
+	}
+	base, head, err := ParseCommitRange(o.Commit)
+	if err != nil {
+		return err
+	}
+	o.Base, o.Head = base, head
+	return nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCommitRange parses the two commits BASE and HEAD of dependencydiff, given as "base...head".
+func ParseCommitRange(commit string) (base, head string, err error) {
+	base, head, found := strings.Cut(commit, "...")
+	if !found || base == "" || head == "" || strings.Contains(head, "...") {
+		return "", "", fmt.Errorf("%w: %q", errCommitRangeInvalid, commit)
+	}
+	return base, head, nil
+}
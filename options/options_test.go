@@ -105,3 +105,228 @@ func TestOptions_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCommitRange(t *testing.T) {
+	t.Parallel()
+	//nolint
+	tests := []struct {
+		name     string
+		commit   string
+		wantBase string
+		wantHead string
+		wantErr  bool
+	}{
+		{name: "SHAs", commit: "70d045b...4a88dac", wantBase: "70d045b", wantHead: "4a88dac"},
+		{name: "branches", commit: "main...dev", wantBase: "main", wantHead: "dev"},
+		{name: "default commit", commit: "HEAD", wantErr: true},
+		{name: "two dots", commit: "main..dev", wantErr: true},
+		{name: "empty base", commit: "...dev", wantErr: true},
+		{name: "empty head", commit: "main...", wantErr: true},
+		{name: "three commits", commit: "main...dev...feature", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			base, head, err := ParseCommitRange(tt.commit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCommitRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if base != tt.wantBase || head != tt.wantHead {
+				t.Errorf("ParseCommitRange() = %s, %s, want %s, %s", base, head, tt.wantBase, tt.wantHead)
+			}
+		})
+	}
+}
+
+// Cannot run parallel tests because of the ENV variables.
+//nolint
+func TestOptions_ValidateDepdiff(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      Options
+		experimental bool
+		wantErr      bool
+	}{
+		{
+			name: "valid options",
+			options: Options{
+				Repo: "ossf/scorecard", Base: "main", Head: "dev", Workers: 1,
+				ChangeTypes: []string{"added", "Updated"}, ChecksToRun: []string{"License", "code-review"},
+			},
+			experimental: true,
+			wantErr:      false,
+		},
+		{
+			name:         "experimental features are disabled",
+			options:      Options{Repo: "ossf/scorecard", Base: "main", Head: "dev", Workers: 1},
+			experimental: false,
+			wantErr:      true,
+		},
+		{
+			name:         "repo is empty",
+			options:      Options{Base: "main", Head: "dev", Workers: 1},
+			experimental: true,
+			wantErr:      true,
+		},
+		{
+			name:         "base is empty",
+			options:      Options{Repo: "ossf/scorecard", Head: "dev", Workers: 1},
+			experimental: true,
+			wantErr:      true,
+		},
+		{
+			name:         "head is empty",
+			options:      Options{Repo: "ossf/scorecard", Base: "main", Workers: 1},
+			experimental: true,
+			wantErr:      true,
+		},
+		{
+			name: "unknown change type",
+			options: Options{
+				Repo: "ossf/scorecard", Base: "main", Head: "dev", Workers: 1,
+				ChangeTypes: []string{"renamed"},
+			},
+			experimental: true,
+			wantErr:      true,
+		},
+		{
+			name: "unknown check",
+			options: Options{
+				Repo: "ossf/scorecard", Base: "main", Head: "dev", Workers: 1,
+				ChecksToRun: []string{"Not-A-Check"},
+			},
+			experimental: true,
+			wantErr:      true,
+		},
+		{
+			name:         "workers is not positive",
+			options:      Options{Repo: "ossf/scorecard", Base: "main", Head: "dev"},
+			experimental: true,
+			wantErr:      true,
+		},
+		{
+			name: "template format without a template file",
+			options: Options{
+				Repo: "ossf/scorecard", Base: "main", Head: "dev", Workers: 1,
+				Format: FormatTemplate,
+			},
+			experimental: true,
+			wantErr:      true,
+		},
+		{
+			name: "template format with a template file",
+			options: Options{
+				Repo: "ossf/scorecard", Base: "main", Head: "dev", Workers: 1,
+				Format: FormatTemplate, TemplateFile: "report.tmpl",
+			},
+			experimental: true,
+			wantErr:      false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.experimental {
+				os.Setenv(EnvVarScorecardExperimental, "1")
+				defer os.Unsetenv(EnvVarScorecardExperimental)
+			}
+
+			if err := tt.options.ValidateDepdiff(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.ValidateDepdiff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Cannot run parallel tests because of the ENV variables.
+//nolint
+func TestNew_DepdiffEnvVars(t *testing.T) {
+	t.Setenv("DEPDIFF_BASE", "main")
+	t.Setenv("DEPDIFF_HEAD", "dev")
+	t.Setenv("DEPDIFF_CHANGE_TYPES", "added,removed")
+	t.Setenv("DEPDIFF_WORKERS", "4")
+	t.Setenv("DEPDIFF_CACHE_DIR", "/tmp/depdiff")
+	t.Setenv("DEPDIFF_POLICY_FILE", "policy.yml")
+	t.Setenv("DEPDIFF_RESULTS_FILE", "results.json")
+	o := New()
+	if o.Base != "main" || o.Head != "dev" {
+		t.Errorf("got base %q and head %q, want main and dev", o.Base, o.Head)
+	}
+	if len(o.ChangeTypes) != 2 || o.ChangeTypes[0] != "added" || o.ChangeTypes[1] != "removed" {
+		t.Errorf("got change types %v, want [added removed]", o.ChangeTypes)
+	}
+	if o.Workers != 4 || o.CacheDir != "/tmp/depdiff" {
+		t.Errorf("got workers %d and cache dir %q, want 4 and /tmp/depdiff", o.Workers, o.CacheDir)
+	}
+	if o.DepdiffPolicyFile != "policy.yml" || o.ResultsFile != "results.json" {
+		t.Errorf("got policy file %q and results file %q", o.DepdiffPolicyFile, o.ResultsFile)
+	}
+}
+
+//nolint
+func TestOptions_SetDepdiffCommits(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		options  Options
+		wantBase string
+		wantHead string
+		wantErr  bool
+	}{
+		{name: "commit range", options: Options{Commit: "main...dev"}, wantBase: "main", wantHead: "dev"},
+		{
+			name:     "default commit keeps base and head",
+			options:  Options{Commit: DefaultCommit, Base: "a", Head: "b"},
+			wantBase: "a", wantHead: "b",
+		},
+		{
+			name:     "commit range overrides base and head",
+			options:  Options{Commit: "main...dev", Base: "a", Head: "b"},
+			wantBase: "main", wantHead: "dev",
+		},
+		{name: "invalid commit", options: Options{Commit: "main"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.options.SetDepdiffCommits(); (err != nil) != tt.wantErr {
+				t.Fatalf("Options.SetDepdiffCommits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (tt.options.Base != tt.wantBase || tt.options.Head != tt.wantHead) {
+				t.Errorf("got %s...%s, want %s...%s", tt.options.Base, tt.options.Head, tt.wantBase, tt.wantHead)
+			}
+		})
+	}
+}
+
+//nolint
+func TestEnvVarOf(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		field     string
+		want      string
+		wantPanic bool
+	}{
+		{name: "base", field: "Base", want: "DEPDIFF_BASE"},
+		{name: "dry run", field: "DryRun", want: "DEPDIFF_DRY_RUN"},
+		{name: "no env tag", field: "Repo", wantPanic: true},
+		{name: "unknown field", field: "Unknown", wantPanic: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			defer func() {
+				if r := recover(); (r != nil) != tt.wantPanic {
+					t.Errorf("envVarOf(%q) panic = %v, wantPanic %v", tt.field, r, tt.wantPanic)
+				}
+			}()
+			if got := envVarOf(tt.field); got != tt.want {
+				t.Errorf("envVarOf(%q) = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package osv
+
+import (
+	"strings"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+)
+
+// osvURL is the prefix of the OSV web page of a vulnerability.
+const osvURL = "https://osv.dev/vulnerability/"
+
+// Match adds the vulnerabilities found in the database to the dependencies in the results. Removed dependencies
+// are matched as well, so that vulnerabilities already present at BASE are known to be pre-existing.
+// Dependencies are matched by their OSV ecosystem and version, so the ecosystem naming must already be
+// mapped from GitHub to OSV. Vulnerabilities already reported for a dependency, by ID or alias, are skipped.
+func (db *Database) Match(results []pkg.DependencyCheckResult) {
+	for i := range results {
+		r := &results[i]
+		if r.ChangeType == nil || r.Ecosystem == nil || r.Version == nil {
+			continue
+		}
+		r.Vulnerabilities = append(r.Vulnerabilities, db.find(r)...)
+	}
+}
+
+// find returns the vulnerabilities affecting the dependency which are not reported yet.
+func (db *Database) find(r *pkg.DependencyCheckResult) []pkg.Vulnerability {
+	reported := map[string]bool{}
+	for _, v := range r.Vulnerabilities {
+		reported[v.ID] = true
+	}
+	var vulns []pkg.Vulnerability
+	for _, e := range db.entries[packageKey(*r.Ecosystem, r.Name)] {
+		if isReported(e, reported) || !e.affects(*r.Ecosystem, r.Name, *r.Version) {
+			continue
+		}
+		reported[e.ID] = true
+		vulns = append(vulns, e.toVulnerability())
+	}
+	return vulns
+}
+
+func isReported(e *entry, reported map[string]bool) bool {
+	if reported[e.ID] {
+		return true
+	}
+	for _, alias := range e.Aliases {
+		if reported[alias] {
+			return true
+		}
+	}
+	return false
+}
+
+// toVulnerability converts an OSV entry to a pkg.Vulnerability.
+func (e *entry) toVulnerability() pkg.Vulnerability {
+	severity := pkg.SeverityLevel(strings.ToUpper(e.DatabaseSpecific.Severity))
+	if !severity.IsValid() {
+		severity = pkg.Unknown
+	}
+	title := e.Summary
+	if title == "" {
+		title = strings.SplitN(e.Details, "\n", 2)[0]
+	}
+	vuln := pkg.Vulnerability{
+		Source:        string(pkg.OSV),
+		ID:            e.ID,
+		SourceURL:     osvURL + e.ID,
+		Title:         title,
+		Description:   e.Details,
+		Severity:      severity,
+		DisclosedTime: e.Published,
+	}
+	for _, ref := range e.References {
+		vuln.ReferenceURLs = append(vuln.ReferenceURLs, ref.URL)
+	}
+	return vuln
+}
+
+// FixSource is a source of the versions of packages fixing vulnerabilities, such as the OSV database or the
+// GitHub Advisory Database.
+type FixSource interface {
+	// FixedVersion returns the minimal version fixing the vulnerability for the package version, or an empty
+	// string if the version is not affected or no fix is known.
+	FixedVersion(id, ecosystem, name, version string) (string, error)
+}
+
+// SuggestFixes sets the minimal fixed version of each vulnerability of the added and updated dependencies
+// in the results, using the first source which knows a fix, and suggests the minimal version of each
+// dependency fixing all of its vulnerabilities of which a fix is known.
+func SuggestFixes(results []pkg.DependencyCheckResult, sources ...FixSource) error {
+	for i := range results {
+		r := &results[i]
+		if r.ChangeType == nil || r.Ecosystem == nil || r.Version == nil {
+			continue
+		}
+		if *r.ChangeType != pkg.Added && *r.ChangeType != pkg.Updated {
+			continue
+		}
+		suggested := ""
+		for j := range r.Vulnerabilities {
+			v := &r.Vulnerabilities[j]
+			for _, source := range sources {
+				if v.FixedVersion != "" {
+					break
+				}
+				fixed, err := source.FixedVersion(v.ID, *r.Ecosystem, r.Name, *r.Version)
+				if err != nil {
+					return err
+				}
+				v.FixedVersion = fixed
+			}
+			if v.FixedVersion != "" && (suggested == "" || Compare(*r.Ecosystem, v.FixedVersion, suggested) > 0) {
+				suggested = v.FixedVersion
+			}
+		}
+		if suggested != "" {
+			r.FixSuggestion = &pkg.FixSuggestion{
+				Version:   suggested,
+				SameMajor: majorVersion(suggested) == majorVersion(*r.Version),
+			}
+		}
+	}
+	return nil
+}
+
+// FixedVersion returns the minimal fixed version of the vulnerability among the entries with the ID or alias,
+// using their affected ranges.
+func (db *Database) FixedVersion(id, ecosystem, name, version string) (string, error) {
+	for _, e := range db.ids[id] {
+		if fixed := e.fixedVersion(ecosystem, name, version); fixed != "" {
+			return fixed, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,315 @@
+// Package osv matches dependencies against an offline copy of the OSV database.
+// The database schema is defined in https://ossf.github.io/osv-schema/.
+package osv
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var errInvalidEntry = errors.New("invalid OSV entry")
+
+// Range types of an affected package.
+const (
+	rangeSemver    = "SEMVER"
+	rangeEcosystem = "ECOSYSTEM"
+	rangeGit       = "GIT"
+)
+
+// entry is a vulnerability entry in the OSV schema.
+type entry struct {
+	Published        time.Time   `json:"published"`
+	ID               string      `json:"id"`
+	Summary          string      `json:"summary"`
+	Details          string      `json:"details"`
+	Aliases          []string    `json:"aliases"`
+	References       []reference `json:"references"`
+	Affected         []affected  `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type reference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// affected is an affected package of a vulnerability, along with the affected versions and ranges.
+type affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges   []affectedRange `json:"ranges"`
+	Versions []string        `json:"versions"`
+}
+
+type affectedRange struct {
+	Type   string  `json:"type"`
+	Events []event `json:"events"`
+}
+
+// event is a version event of an affected range, only one of the fields is set.
+type event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// version returns the version of the event regardless of its kind.
+func (e event) version() string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	case e.LastAffected != "":
+		return e.LastAffected
+	default:
+		return e.Limit
+	}
+}
+
+// Database is an in-memory copy of the OSV database, indexed by ecosystem and package name.
+type Database struct {
+	entries map[string][]*entry
+
+	// ids indexes the entries by their IDs and aliases.
+	ids map[string][]*entry
+}
+
+// LoadDatabase loads an OSV database dump from the given path, which is either a JSON file, a zip archive
+// of JSON files such as the per-ecosystem all.zip exports of OSV, or a directory containing any of them.
+func LoadDatabase(path string) (*Database, error) {
+	db := &Database{entries: map[string][]*entry{}, ids: map[string][]*entry{}}
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return nil
+		case strings.HasSuffix(p, ".zip"):
+			return db.loadZip(p)
+		case strings.HasSuffix(p, ".json"):
+			f, err := os.Open(p)
+			if err != nil {
+				return fmt.Errorf("os.Open: %w", err)
+			}
+			defer f.Close()
+			return db.load(f, p)
+		default:
+			return nil
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error loading the OSV database: %w", err)
+	}
+	return db, nil
+}
+
+func (db *Database) loadZip(path string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("zip.OpenReader: %w", err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if !strings.HasSuffix(f.Name, ".json") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("error opening %s in %s: %w", f.Name, path, err)
+		}
+		err = db.load(rc, f.Name)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *Database) load(r io.Reader, name string) error {
+	e := entry{}
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return fmt.Errorf("error decoding %s: %w", name, err)
+	}
+	if e.ID == "" {
+		return fmt.Errorf("%w: %s has no id", errInvalidEntry, name)
+	}
+	for _, id := range append([]string{e.ID}, e.Aliases...) {
+		db.ids[id] = append(db.ids[id], &e)
+	}
+	// The same entry may affect a package more than once, so index it only once per package.
+	indexed := map[string]bool{}
+	for _, a := range e.Affected {
+		key := packageKey(a.Package.Ecosystem, a.Package.Name)
+		if indexed[key] {
+			continue
+		}
+		indexed[key] = true
+		db.entries[key] = append(db.entries[key], &e)
+	}
+	return nil
+}
+
+// packageKey returns the index key of a package. The ecosystem suffix, such as the release in "Debian:11",
+// is dropped, and ecosystems are case-insensitive since GitHub and OSV disagree on names like NuGet.
+func packageKey(ecosystem, name string) string {
+	ecosystem = strings.ToLower(strings.SplitN(ecosystem, ":", 2)[0])
+	return ecosystem + "/" + normalizeName(ecosystem, name)
+}
+
+// normalizeName normalizes package names of ecosystems in which names are case-insensitive.
+func normalizeName(ecosystem, name string) string {
+	switch ecosystem {
+	case "pypi":
+		// https://peps.python.org/pep-0503/#normalized-names
+		return strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(name))
+	case "nuget", "packagist":
+		return strings.ToLower(name)
+	default:
+		return name
+	}
+}
+
+// affects checks whether the package version is affected by the entry.
+func (e *entry) affects(ecosystem, name, version string) bool {
+	key := packageKey(ecosystem, name)
+	for _, a := range e.Affected {
+		if packageKey(a.Package.Ecosystem, a.Package.Name) != key {
+			continue
+		}
+		for _, v := range a.Versions {
+			// Go module versions are prefixed with "v" in GitHub but not in OSV.
+			if v == version || v == strings.TrimPrefix(version, "v") {
+				return true
+			}
+		}
+		for _, r := range a.Ranges {
+			if r.contains(ecosystem, version) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fixedVersion returns the minimal version fixing the entry for the package version, or an empty string if
+// the version is not affected or no fix is known.
+func (e *entry) fixedVersion(ecosystem, name, version string) string {
+	key := packageKey(ecosystem, name)
+	fixed := ""
+	for _, a := range e.Affected {
+		if packageKey(a.Package.Ecosystem, a.Package.Name) != key {
+			continue
+		}
+		for _, r := range a.Ranges {
+			if !r.contains(ecosystem, version) {
+				continue
+			}
+			// Every range containing the version must be fixed, so the greatest of their fixes is needed.
+			rangeFixed := r.nextFixed(ecosystem, version)
+			if rangeFixed == "" {
+				return ""
+			}
+			if fixed == "" || compareFor(ecosystem)(rangeFixed, fixed) > 0 {
+				fixed = rangeFixed
+			}
+		}
+	}
+	return fixed
+}
+
+// nextFixed returns the first fixed version after the version in the range. Since the affected intervals
+// of a range are disjoint, this is the fix of the interval containing the version.
+func (r affectedRange) nextFixed(ecosystem, version string) string {
+	compare := r.compare(ecosystem)
+	if compare == nil {
+		return ""
+	}
+	for _, e := range sortEvents(r.Events, compare) {
+		if e.Fixed != "" && compare(version, e.Fixed) < 0 {
+			return e.Fixed
+		}
+	}
+	return ""
+}
+
+// compare returns the version comparison function of the range type in the ecosystem, or nil for GIT and
+// unsupported types.
+func (r affectedRange) compare(ecosystem string) func(a, b string) int {
+	switch r.Type {
+	case rangeSemver:
+		return compareSemver
+	case rangeEcosystem:
+		return compareFor(ecosystem)
+	default:
+		return nil
+	}
+}
+
+// contains checks whether the version is within the range. GIT ranges are ordered by the commit graph which
+// is not available offline, so a version is only matched to their events by its commit, as given by a Go
+// pseudo-version or a commit hash, while tagged versions are matched by the enumerated versions.
+func (r affectedRange) contains(ecosystem, version string) bool {
+	if r.Type == rangeGit {
+		return r.containsCommit(version)
+	}
+	compare := r.compare(ecosystem)
+	if compare == nil {
+		return false
+	}
+	affected := false
+	for _, e := range sortEvents(r.Events, compare) {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || compare(version, e.Introduced) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if compare(version, e.Fixed) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if compare(version, e.LastAffected) > 0 {
+				affected = false
+			}
+		case e.Limit != "":
+			if compare(version, e.Limit) >= 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+// containsCommit checks whether the commit of the version is an event of the GIT range which makes it
+// affected, i.e. an introduced or last affected commit.
+func (r affectedRange) containsCommit(version string) bool {
+	commit := commitOf(version)
+	if commit == "" {
+		return false
+	}
+	for _, e := range r.Events {
+		switch {
+		case strings.HasPrefix(e.Introduced, commit), strings.HasPrefix(e.LastAffected, commit):
+			return true
+		case strings.HasPrefix(e.Fixed, commit), strings.HasPrefix(e.Limit, commit):
+			return false
+		}
+	}
+	return false
+}
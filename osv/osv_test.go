@@ -0,0 +1,222 @@
+package osv
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+)
+
+const testEntry = `{
+	"id": "GHSA-test-0001",
+	"aliases": ["CVE-2022-0001"],
+	"summary": "test vulnerability",
+	"affected": [{
+		"package": {"ecosystem": "Go", "name": "example.com/mod"},
+		"ranges": [{
+			"type": "SEMVER",
+			"events": [{"introduced": "1.2.0"}, {"fixed": "1.2.5"}, {"introduced": "0"}, {"fixed": "1.0.1"}]
+		}]
+	}, {
+		"package": {"ecosystem": "PyPI", "name": "Example_Pkg"},
+		"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.0"}, {"last_affected": "2.3.1"}]}],
+		"versions": ["1.9rc1"]
+	}],
+	"database_specific": {"severity": "HIGH"}
+}`
+
+func TestAffectedRange_Contains(t *testing.T) {
+	t.Parallel()
+	semver := affectedRange{
+		Type:   rangeSemver,
+		Events: []event{{Introduced: "1.2.0"}, {Fixed: "1.2.5"}, {Introduced: "0"}, {Fixed: "1.0.1"}},
+	}
+	ecosystem := affectedRange{
+		Type:   rangeEcosystem,
+		Events: []event{{Introduced: "2.0"}, {LastAffected: "2.3.1"}},
+	}
+	git := affectedRange{
+		Type:   rangeGit,
+		Events: []event{{Introduced: "abcdef1234567890"}, {Fixed: "0123456789abcdef"}},
+	}
+	tests := []struct {
+		name      string
+		ecosystem string
+		version   string
+		r         affectedRange
+		want      bool
+	}{
+		{name: "semver before the first fix", r: semver, version: "v1.0.0", want: true},
+		{name: "semver at the first fix", r: semver, version: "1.0.1", want: false},
+		{name: "semver between the ranges", r: semver, version: "1.1.9", want: false},
+		{name: "semver pre-release of an introduced version", r: semver, version: "1.2.0-rc.1", want: false},
+		{name: "semver in the second range", r: semver, version: "1.2.4", want: true},
+		{name: "semver after the last fix", r: semver, version: "1.10.0", want: false},
+		{name: "ecosystem before introduced", r: ecosystem, version: "1.9", want: false},
+		{name: "ecosystem equal to introduced", r: ecosystem, version: "2.0.0", want: true},
+		{name: "ecosystem at last affected", r: ecosystem, version: "2.3.1", want: true},
+		{name: "ecosystem after last affected", r: ecosystem, version: "2.3.2", want: false},
+		{name: "ecosystem pre-release before introduced", r: ecosystem, version: "2.0rc1", want: false},
+		{name: "pypi pre-release before introduced", r: ecosystem, ecosystem: "PyPI", version: "2.0rc1", want: false},
+		{name: "pypi post-release at last affected", r: ecosystem, ecosystem: "PyPI", version: "2.3.1.post1", want: false},
+		{name: "pypi dev release of last affected", r: ecosystem, ecosystem: "PyPI", version: "2.3.1.dev0", want: true},
+		{name: "git tags are not ordered", r: git, version: "v1.0.0", want: false},
+		{name: "git introduced pseudo-version", r: git, ecosystem: "Go", version: "v0.0.0-20220101120000-abcdef123456", want: true},
+		{name: "git fixed commit", r: git, version: "0123456789abcdef", want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.r.contains(tt.ecosystem, tt.version); got != tt.want {
+				t.Errorf("contains(%s) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+// loadTestDatabase writes testEntry to a zip archive and loads it as a database.
+func loadTestDatabase(t *testing.T) *Database {
+	t.Helper()
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "all.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	entryWriter, err := w.Create("GHSA-test-0001.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := entryWriter.Write([]byte(testEntry)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	db, err := LoadDatabase(dir)
+	if err != nil {
+		t.Fatalf("LoadDatabase: %v", err)
+	}
+	return db
+}
+
+func TestDatabase_Match(t *testing.T) {
+	t.Parallel()
+	db := loadTestDatabase(t)
+	added, removed := pkg.Added, pkg.Removed
+	goEco, pypiEco := "Go", "PyPI"
+	vulnerable, fixed, preRelease := "v1.2.3", "v1.2.5", "1.9rc1"
+	results := []pkg.DependencyCheckResult{
+		{Name: "example.com/mod", ChangeType: &added, Ecosystem: &goEco, Version: &vulnerable},
+		{Name: "example.com/mod", ChangeType: &added, Ecosystem: &goEco, Version: &fixed},
+		{Name: "example.com/mod", ChangeType: &removed, Ecosystem: &goEco, Version: &vulnerable},
+		{Name: "example-pkg", ChangeType: &added, Ecosystem: &pypiEco, Version: &preRelease},
+		{
+			Name: "example-pkg", ChangeType: &added, Ecosystem: &pypiEco, Version: &preRelease,
+			Vulnerabilities: []pkg.Vulnerability{{ID: "CVE-2022-0001"}},
+		},
+	}
+	db.Match(results)
+	wantCounts := []int{1, 0, 1, 1, 1}
+	for i, r := range results {
+		if len(r.Vulnerabilities) != wantCounts[i] {
+			t.Errorf("result %d: got %d vulnerabilities, want %d", i, len(r.Vulnerabilities), wantCounts[i])
+		}
+	}
+	v := results[0].Vulnerabilities[0]
+	if v.ID != "GHSA-test-0001" || v.Severity != pkg.High || v.Source != string(pkg.OSV) {
+		t.Errorf("unexpected vulnerability: %+v", v)
+	}
+}
+
+func TestSuggestFixes(t *testing.T) {
+	t.Parallel()
+	db := loadTestDatabase(t)
+	added := pkg.Added
+	goEco := "Go"
+	firstRange, secondRange := "v1.0.0", "v1.2.3"
+	results := []pkg.DependencyCheckResult{
+		{
+			Name: "example.com/mod", ChangeType: &added, Ecosystem: &goEco, Version: &firstRange,
+			Vulnerabilities: []pkg.Vulnerability{{ID: "CVE-2022-0001"}},
+		},
+		{
+			Name: "example.com/mod", ChangeType: &added, Ecosystem: &goEco, Version: &secondRange,
+			Vulnerabilities: []pkg.Vulnerability{{ID: "GHSA-test-0001"}, {ID: "GHSA-unknown"}},
+		},
+	}
+	if err := SuggestFixes(results, db); err != nil {
+		t.Fatalf("SuggestFixes: %v", err)
+	}
+	wantVersions := []string{"1.0.1", "1.2.5"}
+	for i, r := range results {
+		if r.FixSuggestion == nil {
+			t.Fatalf("result %d: no fix suggestion", i)
+		}
+		if r.FixSuggestion.Version != wantVersions[i] || !r.FixSuggestion.SameMajor {
+			t.Errorf("result %d: got suggestion %+v, want version %s in the same major", i, r.FixSuggestion, wantVersions[i])
+		}
+		if r.Vulnerabilities[0].FixedVersion != wantVersions[i] {
+			t.Errorf("result %d: got fixed version %s, want %s", i, r.Vulnerabilities[0].FixedVersion, wantVersions[i])
+		}
+	}
+	if results[1].Vulnerabilities[1].FixedVersion != "" {
+		t.Errorf("got fixed version %s for an unknown vulnerability", results[1].Vulnerabilities[1].FixedVersion)
+	}
+}
+
+func TestCompareFor(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		ecosystem string
+		a, b      string
+		want      int
+	}{
+		// Semantic versions of npm and Go.
+		{ecosystem: "npm", a: "2.0.0-rc.1", b: "2.0.0", want: -1},
+		{ecosystem: "npm", a: "2.0.0-alpha", b: "2.0.0-alpha.1", want: -1},
+		{ecosystem: "npm", a: "2.0.0-alpha.beta", b: "2.0.0-beta", want: -1},
+		{ecosystem: "npm", a: "2.0.0-beta.11", b: "2.0.0-beta.2", want: 1},
+		{ecosystem: "npm", a: "2.0.0-rc.1", b: "2.0.0-beta.11", want: 1},
+		{ecosystem: "Go", a: "v1.2.3+incompatible", b: "v1.2.3", want: 0},
+		{ecosystem: "Go", a: "v0.0.0-20220101120000-abcdef123456", b: "v0.0.1", want: -1},
+		// PEP 440 versions of PyPI.
+		{ecosystem: "PyPI", a: "2.0rc1", b: "2.0", want: -1},
+		{ecosystem: "PyPI", a: "2.0a1", b: "2.0b1", want: -1},
+		{ecosystem: "PyPI", a: "2.0.dev1", b: "2.0a1", want: -1},
+		{ecosystem: "PyPI", a: "2.0a1.dev1", b: "2.0a1", want: -1},
+		{ecosystem: "PyPI", a: "2.0", b: "2.0.post1", want: -1},
+		{ecosystem: "PyPI", a: "2.0.post1.dev1", b: "2.0.post1", want: -1},
+		{ecosystem: "PyPI", a: "2.0-1", b: "2.0.post1", want: 0},
+		{ecosystem: "PyPI", a: "2.0.0", b: "2.0", want: 0},
+		{ecosystem: "PyPI", a: "2.0RC1", b: "2.0.rc.1", want: 0},
+		{ecosystem: "PyPI", a: "2.0-alpha", b: "2.0a0", want: 0},
+		{ecosystem: "PyPI", a: "2.0+local.1", b: "2.0", want: 0},
+		{ecosystem: "PyPI", a: "1!1.0", b: "2.0", want: 1},
+		{ecosystem: "PyPI", a: "2.0.1", b: "2.0rc1", want: 1},
+		// Other ecosystems rank pre-release tags below releases.
+		{ecosystem: "Maven", a: "2.0rc1", b: "2.0", want: -1},
+		{ecosystem: "Maven", a: "2.0-SNAPSHOT", b: "2.0", want: -1},
+		{ecosystem: "Maven", a: "2.0.1", b: "2.0-rc1", want: 1},
+		{ecosystem: "RubyGems", a: "2.0.0.beta1", b: "2.0.0", want: -1},
+		{ecosystem: "RubyGems", a: "1.10", b: "1.9", want: 1},
+		{ecosystem: "NuGet", a: "1.0", b: "1.0.0", want: 0},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.ecosystem+" "+tt.a+" "+tt.b, func(t *testing.T) {
+			t.Parallel()
+			compare := compareFor(tt.ecosystem)
+			if got := compare(tt.a, tt.b); got != tt.want {
+				t.Errorf("compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := compare(tt.b, tt.a); got != -tt.want {
+				t.Errorf("compare(%s, %s) = %d, want %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}
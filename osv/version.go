@@ -0,0 +1,345 @@
+package osv
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// sortEvents returns a copy of the events sorted by their versions, with "introduced: 0" first.
+func sortEvents(events []event, compare func(a, b string) int) []event {
+	sorted := make([]event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Introduced == "0" {
+			return sorted[j].Introduced != "0"
+		}
+		if sorted[j].Introduced == "0" {
+			return false
+		}
+		return compare(sorted[i].version(), sorted[j].version()) < 0
+	})
+	return sorted
+}
+
+// Compare compares two versions of a package in an OSV ecosystem, returning -1, 0 or 1.
+func Compare(ecosystem, a, b string) int {
+	return compareFor(ecosystem)(a, b)
+}
+
+// compareFor returns the version comparison function of an OSV ecosystem.
+func compareFor(ecosystem string) func(a, b string) int {
+	switch strings.ToLower(ecosystem) {
+	case "go", "npm", "crates.io":
+		return compareSemver
+	case "pypi":
+		return comparePEP440
+	default:
+		return compareVersions
+	}
+}
+
+// majorVersion returns the major version of a version, which is its first numeric part.
+func majorVersion(v string) string {
+	for _, part := range splitVersion(strings.TrimPrefix(v, "v")) {
+		if _, err := strconv.ParseUint(part, 10, 64); err == nil {
+			return part
+		}
+	}
+	return ""
+}
+
+// compareSemver compares two semantic versions as defined in https://semver.org, returning -1, 0 or 1.
+// The "v" prefix used by Go modules is ignored, and so is build metadata.
+func compareSemver(a, b string) int {
+	aCore, aPre := splitSemver(a)
+	bCore, bPre := splitSemver(b)
+	if c := compareVersions(aCore, bCore); c != 0 {
+		return c
+	}
+	// A pre-release version has a lower precedence than the normal version.
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	aIDs, bIDs := strings.Split(aPre, "."), strings.Split(bPre, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, aErr := strconv.ParseUint(aIDs[i], 10, 64)
+		bNum, bErr := strconv.ParseUint(bIDs[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInts(aNum, bNum); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			// Numeric identifiers have a lower precedence than alphanumeric ones.
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(aIDs[i], bIDs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInts(uint64(len(aIDs)), uint64(len(bIDs)))
+}
+
+// splitSemver splits a semantic version into its core version and its pre-release identifiers.
+func splitSemver(v string) (string, string) {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	if i := strings.Index(v, "-"); i >= 0 {
+		return v[:i], v[i+1:]
+	}
+	return v, ""
+}
+
+// preReleaseTags are the non-numeric version parts marking pre-releases in most ecosystems.
+var preReleaseTags = map[string]bool{
+	"a": true, "alpha": true, "b": true, "beta": true, "c": true, "rc": true, "cr": true, "pre": true,
+	"preview": true, "dev": true, "m": true, "milestone": true, "snapshot": true,
+}
+
+// compareVersions compares two versions of an ecosystem, returning -1, 0 or 1. The versions are split into
+// numeric and non-numeric parts which are compared in order, numerically and lexically respectively, except
+// that pre-release tags such as rc rank below numbers and the end of a version, e.g. 2.0rc1 < 2.0 < 2.0.1.
+// This approximates the ordering of most ecosystems without implementing each of their specifications.
+func compareVersions(a, b string) int {
+	aParts := splitVersion(strings.ToLower(strings.TrimPrefix(a, "v")))
+	bParts := splitVersion(strings.ToLower(strings.TrimPrefix(b, "v")))
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.ParseUint(aParts[i], 10, 64)
+		bNum, bErr := strconv.ParseUint(bParts[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInts(aNum, bNum); c != 0 {
+				return c
+			}
+			continue
+		case aErr == nil && preReleaseTags[bParts[i]]:
+			return 1
+		case bErr == nil && preReleaseTags[aParts[i]]:
+			return -1
+		}
+		if c := strings.Compare(aParts[i], bParts[i]); c != 0 {
+			return c
+		}
+	}
+	// A version followed by a pre-release tag is lower than the version itself.
+	switch {
+	case len(aParts) > len(bParts) && preReleaseTags[aParts[len(bParts)]]:
+		return -1
+	case len(bParts) > len(aParts) && preReleaseTags[bParts[len(aParts)]]:
+		return 1
+	}
+	// Trailing zero parts don't make a version greater, e.g. 1.0 equals 1.0.0.
+	return compareInts(trimZeros(aParts), trimZeros(bParts))
+}
+
+// pep440Version is a version parsed as specified by PEP 440, where missing parts are -1.
+type pep440Version struct {
+	epoch   int64
+	release []uint64
+	// prePhase is 0, 1 and 2 for the a, b and rc pre-releases respectively.
+	prePhase, preNumber int64
+	post, dev           int64
+}
+
+// pep440Pattern matches versions as specified by PEP 440, including the alternative spellings of its
+// normalization rules. The local version label is ignored.
+var pep440Pattern = regexp.MustCompile(`^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d+)?)?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d+)?)?` +
+	`(?:[-_.]?(dev)[-_.]?(\d+)?)?(?:\+[a-z0-9]+(?:[-_.][a-z0-9]+)*)?$`)
+
+var pep440PrePhases = map[string]int64{
+	"a": 0, "alpha": 0, "b": 1, "beta": 1, "c": 2, "rc": 2, "pre": 2, "preview": 2,
+}
+
+// parsePEP440 parses a PEP 440 version, returning false if it is not valid.
+func parsePEP440(v string) (pep440Version, bool) {
+	m := pep440Pattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(v)))
+	if m == nil {
+		return pep440Version{}, false
+	}
+	number := func(s string, missing int64) int64 {
+		if s == "" {
+			return missing
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return missing
+		}
+		return n
+	}
+	parsed := pep440Version{epoch: number(m[1], 0), prePhase: -1, preNumber: -1, post: -1, dev: -1}
+	for _, part := range strings.Split(m[2], ".") {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return pep440Version{}, false
+		}
+		parsed.release = append(parsed.release, n)
+	}
+	if m[3] != "" {
+		parsed.prePhase, parsed.preNumber = pep440PrePhases[m[3]], number(m[4], 0)
+	}
+	switch {
+	case m[5] != "":
+		parsed.post = number(m[5], 0)
+	case m[6] != "":
+		parsed.post = number(m[7], 0)
+	}
+	if m[8] != "" {
+		parsed.dev = number(m[9], 0)
+	}
+	return parsed, true
+}
+
+// comparePEP440 compares two versions as specified by PEP 440, returning -1, 0 or 1. Versions which are not
+// valid PEP 440 versions are compared by compareVersions.
+func comparePEP440(a, b string) int {
+	aVersion, aOK := parsePEP440(a)
+	bVersion, bOK := parsePEP440(b)
+	if !aOK || !bOK {
+		return compareVersions(a, b)
+	}
+	if c := compareSigned(aVersion.epoch, bVersion.epoch); c != 0 {
+		return c
+	}
+	if c := compareReleases(aVersion.release, bVersion.release); c != 0 {
+		return c
+	}
+	aPre, bPre := aVersion.preKey(), bVersion.preKey()
+	for i := range aPre {
+		if c := compareSigned(aPre[i], bPre[i]); c != 0 {
+			return c
+		}
+	}
+	// A post-release is greater than no post-release, and no development release is greater than one.
+	if c := compareSigned(aVersion.post, bVersion.post); c != 0 {
+		return c
+	}
+	return compareSigned(devKey(aVersion.dev), devKey(bVersion.dev))
+}
+
+// preKey orders the pre-release of a version: a development release of a final release ranks below its
+// pre-releases, which rank below the final release.
+func (v pep440Version) preKey() [2]int64 {
+	switch {
+	case v.prePhase >= 0:
+		return [2]int64{v.prePhase, v.preNumber}
+	case v.post < 0 && v.dev >= 0:
+		return [2]int64{math.MinInt64, 0}
+	default:
+		return [2]int64{math.MaxInt64, 0}
+	}
+}
+
+func devKey(dev int64) int64 {
+	if dev < 0 {
+		return math.MaxInt64
+	}
+	return dev
+}
+
+// compareReleases compares release segments, in which trailing zeros are insignificant.
+func compareReleases(a, b []uint64) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y uint64
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := compareInts(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareSigned(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// commitOf returns the commit of a version given as a commit hash or as a Go pseudo-version, such as
+// v0.0.0-20220101120000-abcdef123456, or an empty string for other versions.
+func commitOf(version string) string {
+	if i := strings.LastIndex(version, "-"); i >= 0 && strings.HasPrefix(version, "v") {
+		version = version[i+1:]
+	}
+	if len(version) < 7 || len(version) > 40 {
+		return ""
+	}
+	for _, r := range version {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return ""
+		}
+	}
+	return version
+}
+
+// splitVersion splits a version into its numeric and non-numeric parts, dropping separators.
+func splitVersion(v string) []string {
+	parts := []string{}
+	current := ""
+	for _, r := range v {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if current != "" {
+				parts = append(parts, current)
+			}
+			current = ""
+			continue
+		}
+		if current != "" && unicode.IsDigit(r) != unicode.IsDigit(rune(current[len(current)-1])) {
+			parts = append(parts, current)
+			current = ""
+		}
+		current += string(r)
+	}
+	if current != "" {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// trimZeros returns the number of parts left when trailing zero parts are removed.
+func trimZeros(parts []string) uint64 {
+	n := len(parts)
+	for n > 0 {
+		if num, err := strconv.ParseUint(parts[n-1], 10, 64); err != nil || num != 0 {
+			break
+		}
+		n--
+	}
+	return uint64(n)
+}
+
+func compareInts(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,130 @@
+package pkg
+
+// DependencyChange is a change of a dependency between the BASE and HEAD commits.
+type DependencyChange struct {
+	// Old is the dependency at BASE, which is nil for an added dependency.
+	Old *DependencyCheckResult
+
+	// New is the dependency at HEAD, which is nil for a removed dependency.
+	New *DependencyCheckResult
+
+	// ChangeType indicates whether the dependency is added, updated, or removed.
+	ChangeType ChangeType
+}
+
+// PairDependencyChanges pairs dependency-diff results into dependency changes. GitHub reports an updated
+// dependency as a removed entry and an added one, which are paired by manifest, ecosystem and name.
+// Changes are returned in the order in which their first entry appears in the results.
+func PairDependencyChanges(results []DependencyCheckResult) []DependencyChange {
+	// The n-th removed entry of a key is paired with the n-th added entry of the same key.
+	removed := map[string][]int{}
+	for i := range results {
+		if isChangeType(&results[i], Removed) {
+			key := dependencyKey(&results[i])
+			removed[key] = append(removed[key], i)
+		}
+	}
+	partners := map[int]int{}
+	for i := range results {
+		if !isChangeType(&results[i], Added) {
+			continue
+		}
+		key := dependencyKey(&results[i])
+		if queue := removed[key]; len(queue) > 0 {
+			partners[i], partners[queue[0]] = queue[0], i
+			removed[key] = queue[1:]
+		}
+	}
+	changes := []DependencyChange{}
+	paired := map[int]bool{}
+	for i := range results {
+		r := &results[i]
+		if r.ChangeType == nil || paired[i] {
+			continue
+		}
+		if j, found := partners[i]; found {
+			paired[j] = true
+			oldDep, newDep := r, &results[j]
+			if *r.ChangeType == Added {
+				oldDep, newDep = newDep, oldDep
+			}
+			changes = append(changes, DependencyChange{Old: oldDep, New: newDep, ChangeType: Updated})
+			continue
+		}
+		switch *r.ChangeType {
+		case Added, Updated:
+			changes = append(changes, DependencyChange{New: r, ChangeType: *r.ChangeType})
+		case Removed:
+			changes = append(changes, DependencyChange{Old: r, ChangeType: Removed})
+		}
+	}
+	return changes
+}
+
+func isChangeType(r *DependencyCheckResult, ct ChangeType) bool {
+	return r.ChangeType != nil && *r.ChangeType == ct
+}
+
+func dependencyKey(r *DependencyCheckResult) string {
+	return derefString(r.ManifestPath) + "|" + derefString(r.Ecosystem) + "|" + r.Name
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// DependencyVulnerability is a vulnerability of a dependency.
+type DependencyVulnerability struct {
+	// Dependency is the dependency with the vulnerability.
+	Dependency *DependencyCheckResult
+
+	// Vulnerability is the vulnerability of the dependency.
+	Vulnerability Vulnerability
+}
+
+// VulnerabilityChanges are the vulnerabilities introduced, fixed and left unchanged by dependency changes.
+type VulnerabilityChanges struct {
+	// Introduced are vulnerabilities found at HEAD but not at BASE.
+	Introduced []DependencyVulnerability
+
+	// Fixed are vulnerabilities found at BASE but not at HEAD.
+	Fixed []DependencyVulnerability
+
+	// Unchanged are vulnerabilities found at both BASE and HEAD, which are pre-existing.
+	Unchanged []DependencyVulnerability
+}
+
+// GetVulnerabilityChanges compares the vulnerabilities of the old and new versions of the changed dependencies.
+func GetVulnerabilityChanges(changes []DependencyChange) VulnerabilityChanges {
+	vc := VulnerabilityChanges{}
+	for _, c := range changes {
+		oldIDs, newIDs := map[string]bool{}, map[string]bool{}
+		if c.Old != nil {
+			for _, v := range c.Old.Vulnerabilities {
+				oldIDs[v.ID] = true
+			}
+		}
+		if c.New != nil {
+			for _, v := range c.New.Vulnerabilities {
+				newIDs[v.ID] = true
+				dv := DependencyVulnerability{Dependency: c.New, Vulnerability: v}
+				if oldIDs[v.ID] {
+					vc.Unchanged = append(vc.Unchanged, dv)
+				} else {
+					vc.Introduced = append(vc.Introduced, dv)
+				}
+			}
+		}
+		if c.Old != nil {
+			for _, v := range c.Old.Vulnerabilities {
+				if !newIDs[v.ID] {
+					vc.Fixed = append(vc.Fixed, DependencyVulnerability{Dependency: c.Old, Vulnerability: v})
+				}
+			}
+		}
+	}
+	return vc
+}
@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ossf/scorecard/v4/checker"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sce "github.com/ossf/scorecard/v4/errors"
+)
+
+// DependencydiffResultsAsCSV exports dependencydiff results as a flat table with a row per dependency change,
+// separated by the given comma, e.g. ',' for CSV or '\t' for TSV. Each check run on any of the dependencies
+// gets a score column, and unknown scores are left empty. The status tells whether the checks ran, would run in
+// a dry run, or why they were skipped.
+func DependencydiffResultsAsCSV(depdiffResults []DependencyCheckResult,
+	checkDocs docs.Doc, policy *DependencydiffPolicy, comma rune, writer io.Writer,
+) error {
+	view, err := NewReportView(depdiffResults, checkDocs, policy)
+	if err != nil {
+		return err
+	}
+	header := []string{
+		"change_type", "ecosystem", "name", "old_version", "new_version", "manifest", "source_repository",
+		"status", "score",
+	}
+	header = append(header, view.Checks...)
+	header = append(header, "vulnerabilities", "error")
+	w := csv.NewWriter(writer)
+	w.Comma = comma
+	if err := w.Write(header); err != nil {
+		return sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("csv.Write: %v", err))
+	}
+	for _, changes := range [][]ChangeView{view.Added, view.Updated, view.Removed} {
+		for _, cv := range changes {
+			score := ""
+			if cv.Score != checker.InconclusiveResultScore {
+				score = fmt.Sprintf("%.1f", cv.Score)
+			}
+			row := []string{
+				string(cv.ChangeType), cv.Ecosystem, cv.Name, cv.OldVersion, cv.NewVersion, cv.ManifestPath,
+				derefString(cv.Dependency.SourceRepository), string(cv.Status), score,
+			}
+			checkScores := map[string]string{}
+			for _, c := range cv.Checks {
+				if c.Score != checker.InconclusiveResultScore {
+					checkScores[c.Name] = fmt.Sprintf("%d", c.Score)
+				}
+			}
+			for _, name := range view.Checks {
+				row = append(row, checkScores[name])
+			}
+			row = append(row, fmt.Sprintf("%d", len(cv.Vulnerabilities)), cv.Error)
+			for i := range row {
+				row[i] = escapeSpreadsheetFormula(row[i])
+			}
+			if err := w.Write(row); err != nil {
+				return sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("csv.Write: %v", err))
+			}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("csv.Flush: %v", err))
+	}
+	return nil
+}
+
+// escapeSpreadsheetFormula prevents a cell from being evaluated as a formula by spreadsheets, since names
+// and errors come from untrusted sources. Spreadsheets also evaluate the cells whose formula is preceded by a
+// tab or a carriage return.
+func escapeSpreadsheetFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,134 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+)
+
+func TestDependencydiffResultsAsCSV(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	results := readTestResults(t)
+	want := [][]string{
+		{
+			"change_type", "ecosystem", "name", "old_version", "new_version", "manifest", "source_repository", "status",
+			"score", "Code-Review", "License", "vulnerabilities", "error",
+		},
+		{
+			"added", "npm", "vulnerable", "", "1.0.0", "package.json", "github.com/owner/vulnerable", "evaluated",
+			"0.0", "", "0", "1", "",
+		},
+		{
+			"added", "npm", "unreachable", "", "1.0.0", "package.json", "github.com/owner/unreachable", "failed",
+			"", "", "", "0", "repo unreachable: not found",
+		},
+		// Cells which spreadsheets would evaluate as formulas are escaped.
+		{
+			"added", "npm", `'=HYPERLINK("<script>")`, "", "1.0.0", "package.json", "", "skipped-no-source",
+			"", "", "", "0", "",
+		},
+		// The inconclusive Code-Review check has no score.
+		{
+			"updated", "Go", "lib", "1.0.0", "2.0.0", "go.mod", "github.com/owner/lib", "evaluated",
+			"9.0", "", "9", "0", "",
+		},
+		{"removed", "npm", "gone", "1.0.0", "", "package.json", "", "skipped-change-type", "", "", "", "0", ""},
+	}
+	//nolint
+	tests := []struct {
+		name  string
+		comma rune
+	}{
+		{name: "csv", comma: ','},
+		{name: "tsv", comma: '\t'},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := DependencydiffResultsAsCSV(results, checkDocs, nil, tt.comma, &buf); err != nil {
+				t.Fatalf("DependencydiffResultsAsCSV: %v", err)
+			}
+			r := csv.NewReader(&buf)
+			r.Comma = tt.comma
+			got, err := r.ReadAll()
+			if err != nil {
+				t.Fatalf("ReadAll: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got records\n%q\nwant\n%q", got, want)
+			}
+		})
+	}
+}
+
+func TestDependencydiffResultsAsCSV_DryRun(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := DependencydiffResultsAsCSV(dryRunResults(), checkDocs, nil, ',', &buf); err != nil {
+		t.Fatalf("DependencydiffResultsAsCSV: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	// No checks ran, so there are no check columns.
+	if len(records[0]) != 11 {
+		t.Errorf("got columns %v, want no check columns", records[0])
+	}
+	got := map[string]DependencyStatus{}
+	for _, record := range records[1:] {
+		got[record[columns["name"]]] = DependencyStatus(record[columns["status"]])
+		if score := record[columns["score"]]; score != "" {
+			t.Errorf("%s has score %s in a dry run", record[columns["name"]], score)
+		}
+	}
+	if !reflect.DeepEqual(got, dryRunStatuses) {
+		t.Errorf("got statuses %v, want %v", got, dryRunStatuses)
+	}
+}
+
+func TestEscapeSpreadsheetFormula(t *testing.T) {
+	t.Parallel()
+	//nolint
+	tests := []struct {
+		name string
+		cell string
+		want string
+	}{
+		{name: "empty", cell: "", want: ""},
+		{name: "name", cell: "lodash", want: "lodash"},
+		{name: "equals", cell: "=1+1", want: "'=1+1"},
+		{name: "plus", cell: "+1", want: "'+1"},
+		{name: "minus", cell: "-1", want: "'-1"},
+		{name: "at", cell: "@SUM(A1)", want: "'@SUM(A1)"},
+		{name: "tab", cell: "\t=1+1", want: "'\t=1+1"},
+		{name: "carriage return", cell: "\r=1+1", want: "'\r=1+1"},
+		{name: "formula within", cell: "a=1", want: "a=1"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := escapeSpreadsheetFormula(tt.cell); got != tt.want {
+				t.Errorf("escapeSpreadsheetFormula(%q) = %q, want %q", tt.cell, got, tt.want)
+			}
+		})
+	}
+}
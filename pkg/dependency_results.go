@@ -1,12 +1,14 @@
 package pkg
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 
+	"github.com/aidenwang9867/depdiffvis/options"
 	"github.com/ossf/scorecard/v4/docs/checks"
+	sce "github.com/ossf/scorecard/v4/errors"
 	"github.com/ossf/scorecard/v4/log"
-	"github.com/ossf/scorecard/v4/options"
 	scpkg "github.com/ossf/scorecard/v4/pkg"
 )
 
@@ -32,6 +34,22 @@ func (ct *ChangeType) IsValid() bool {
 	}
 }
 
+// DependencyStatus is the status of running the Scorecard checks on a dependency.
+type DependencyStatus string
+
+const (
+	// StatusEvaluated suggests the Scorecard checks ran successfully on the dependency.
+	StatusEvaluated DependencyStatus = "evaluated"
+	// StatusSkippedNoSource suggests the dependency is skipped since its source repository is unknown.
+	StatusSkippedNoSource DependencyStatus = "skipped-no-source"
+	// StatusSkippedChangeType suggests the dependency is skipped since its change type is not to be checked.
+	StatusSkippedChangeType DependencyStatus = "skipped-change-type"
+	// StatusFailed suggests running the Scorecard checks on the dependency failed.
+	StatusFailed DependencyStatus = "failed"
+	// StatusPlanned suggests the Scorecard checks would run on the dependency, but did not since it is a dry run.
+	StatusPlanned DependencyStatus = "planned"
+)
+
 // ScorecardResultWithError is used for the dependency-diff module to record the scorecard result
 // and a potential error field if the Scorecard run fails.
 type ScorecardResultWithError struct {
@@ -62,22 +80,75 @@ type DependencyCheckResult struct {
 	// Version is the package version of the dependency.
 	Version *string
 
+	// License is the declared SPDX license expression of the dependency at Version.
+	License *string
+
 	// ScorecardResultWithError is the scorecard checking results of the dependency.
 	ScorecardResultWithError ScorecardResultWithError
 
+	// Status is the status of running the Scorecard checks on the dependency.
+	Status DependencyStatus
+
+	// Vulnerabilities is a list of known vulnerabilities of the dependency at Version.
+	Vulnerabilities []Vulnerability
+
+	// FixSuggestion is the version suggested to fix the vulnerabilities, which is nil if no fix is known.
+	FixSuggestion *FixSuggestion
+
 	// Name is the name of the dependency.
 	Name string
 }
 
-// FormatDependencydiffResults formats dependencydiff results.
+// FormatDependencydiffResults formats dependencydiff results in the format of the options, and writes them to
+// the results file of the options, or to stdout if no results file is given. Nothing is written if formatting
+// fails, so that a failure never leaves a partial results file.
 func FormatDependencydiffResults(
 	opts *options.Options,
 	depdiffResults []DependencyCheckResult,
 	doc checks.Doc,
+	policy *DependencydiffPolicy,
 ) error {
-	err := DependencydiffResultsAsJSON(depdiffResults, log.ParseLevel(opts.LogLevel), doc, os.Stdout)
+	output := &bytes.Buffer{}
+	var err error
+	switch opts.Format {
+	case options.FormatJSON:
+		err = DependencydiffResultsAsJSON(depdiffResults, log.ParseLevel(opts.LogLevel), doc, policy, output)
+	case options.FormatSarif:
+		err = DependencydiffResultsAsSARIF(depdiffResults, doc, policy, output)
+	case options.FormatHTML:
+		err = DependencydiffResultsAsHTML(depdiffResults, log.ParseLevel(opts.LogLevel), doc, policy, output)
+	case options.FormatJUnit:
+		err = DependencydiffResultsAsJUnit(depdiffResults, doc, policy, output)
+	case options.FormatCSV:
+		err = DependencydiffResultsAsCSV(depdiffResults, doc, policy, ',', output)
+	case options.FormatTSV:
+		err = DependencydiffResultsAsCSV(depdiffResults, doc, policy, '\t', output)
+	case options.FormatDOT:
+		err = DependencydiffResultsAsDOT(depdiffResults, doc, policy, output)
+	case options.FormatMermaid:
+		err = DependencydiffResultsAsMermaid(depdiffResults, doc, policy, output)
+	case options.FormatTemplate:
+		err = DependencydiffResultsAsTemplate(
+			depdiffResults, log.ParseLevel(opts.LogLevel), doc, policy, opts.TemplateFile, output,
+		)
+	default:
+		err = sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf(
+			"invalid format flag: %v. Expected [%s, %s, %s, %s, %s, %s, %s, %s, %s]",
+			opts.Format, options.FormatJSON, options.FormatSarif, options.FormatHTML, options.FormatJUnit,
+			options.FormatCSV, options.FormatTSV, options.FormatDOT, options.FormatMermaid, options.FormatTemplate,
+		))
+	}
 	if err != nil {
 		return fmt.Errorf("failed to output dependencydiff results: %w", err)
 	}
+	if opts.ResultsFile == "" {
+		if _, err := output.WriteTo(os.Stdout); err != nil {
+			return fmt.Errorf("failed to write dependencydiff results: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(opts.ResultsFile, output.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write the results file: %w", err)
+	}
 	return nil
 }
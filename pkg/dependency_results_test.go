@@ -0,0 +1,142 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aidenwang9867/depdiffvis/options"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sce "github.com/ossf/scorecard/v4/errors"
+)
+
+func TestChangeTypeIsValid(t *testing.T) {
+	t.Parallel()
+	// The change types validated by the options must be exactly the valid change types.
+	for _, name := range options.DepdiffChangeTypes {
+		ct := ChangeType(name)
+		if !ct.IsValid() {
+			t.Errorf("options change type %s is not valid", name)
+		}
+	}
+	for _, ct := range []ChangeType{Added, Updated, Removed} {
+		found := false
+		for _, name := range options.DepdiffChangeTypes {
+			found = found || name == string(ct)
+		}
+		if !found {
+			t.Errorf("change type %s is missing from the options change types", ct)
+		}
+	}
+	invalid := ChangeType("renamed")
+	if invalid.IsValid() {
+		t.Errorf("change type %s is valid", invalid)
+	}
+}
+
+func TestFormatDependencydiffResults(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	added, version := Added, "1.0.0"
+	results := []DependencyCheckResult{{Name: "lib", ChangeType: &added, Version: &version}}
+	//nolint
+	tests := []struct {
+		name   string
+		format string
+		// template is the content of the template file, if any.
+		template string
+		wantErr  error
+		// check checks the content of the results file.
+		check func(t *testing.T, content []byte)
+	}{
+		{
+			name:   "json",
+			format: options.FormatJSON,
+			check: func(t *testing.T, content []byte) {
+				var got []JSONDependencydiffResult
+				if err := json.Unmarshal(content, &got); err != nil {
+					t.Fatalf("json.Unmarshal: %v", err)
+				}
+				if len(got) != 1 || got[0].Name != "lib" || *got[0].Version != version {
+					t.Errorf("got results %+v, want lib @ %s", got, version)
+				}
+			},
+		},
+		{
+			name:   "sarif",
+			format: options.FormatSarif,
+			check: func(t *testing.T, content []byte) {
+				var got struct {
+					Version string            `json:"version"`
+					Runs    []json.RawMessage `json:"runs"`
+				}
+				if err := json.Unmarshal(content, &got); err != nil {
+					t.Fatalf("json.Unmarshal: %v", err)
+				}
+				if got.Version != "2.1.0" || len(got.Runs) != 1 {
+					t.Errorf("got SARIF version %s with %d runs, want 2.1.0 with 1 run", got.Version, len(got.Runs))
+				}
+			},
+		},
+		{
+			name:     "template",
+			format:   options.FormatTemplate,
+			template: "{{range .Added}}{{.Name}}@{{.NewVersion}}{{end}}",
+			check: func(t *testing.T, content []byte) {
+				if got := string(content); got != "lib@"+version {
+					t.Errorf("got %q, want lib@%s", got, version)
+				}
+			},
+		},
+		{
+			// The template fails after rendering a part of the results.
+			name:     "template failing to render",
+			format:   options.FormatTemplate,
+			template: "partial results {{.Unknown}}",
+			wantErr:  sce.ErrScorecardInternal,
+		},
+		{
+			name:    "unsupported format",
+			format:  "yaml",
+			wantErr: sce.ErrScorecardInternal,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			opts := &options.Options{
+				Format:      tt.format,
+				LogLevel:    options.DefaultLogLevel,
+				ResultsFile: filepath.Join(t.TempDir(), "results"),
+			}
+			if tt.template != "" {
+				opts.TemplateFile = filepath.Join(t.TempDir(), "report.tmpl")
+				if err := os.WriteFile(opts.TemplateFile, []byte(tt.template), 0o600); err != nil {
+					t.Fatalf("os.WriteFile: %v", err)
+				}
+			}
+			err := FormatDependencydiffResults(opts, results, checkDocs, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FormatDependencydiffResults() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				// No partial results file is written.
+				if _, err := os.Stat(opts.ResultsFile); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("got results file written, error %v", err)
+				}
+				return
+			}
+			content, err := os.ReadFile(opts.ResultsFile)
+			if err != nil {
+				t.Fatalf("os.ReadFile: %v", err)
+			}
+			tt.check(t, content)
+		})
+	}
+}
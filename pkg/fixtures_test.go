@@ -0,0 +1,51 @@
+package pkg
+
+import (
+	"os"
+	"testing"
+)
+
+// testResultsFile holds the dependency changes shared by the tests of the report formats, saved in the JSON
+// format and decoded with DependencydiffResultsFromJSON.
+const testResultsFile = "testdata/dependencydiff.json"
+
+// readTestResults reads the dependency changes of testResultsFile: the update of lib, fixing GHSA-low, and the
+// addition of vulnerable, introducing GHSA-high, which are evaluated; the addition of unreachable, on which
+// the checks failed; and the addition of a dependency without a source repository, whose name needs escaping,
+// and the removal of gone, which are skipped.
+func readTestResults(t *testing.T) []DependencyCheckResult {
+	t.Helper()
+	f, err := os.Open(testResultsFile)
+	if err != nil {
+		t.Fatalf("os.Open: %v", err)
+	}
+	defer f.Close()
+	results, err := DependencydiffResultsFromJSON(f)
+	if err != nil {
+		t.Fatalf("DependencydiffResultsFromJSON: %v", err)
+	}
+	return results
+}
+
+// dryRunResults are the results of a dry run, in which the checks would run on the added dependency with a
+// source repository, and are skipped on the others.
+func dryRunResults() []DependencyCheckResult {
+	added, removed := Added, Removed
+	version, manifest, repo := "1.0.0", "go.mod", "github.com/owner/planned"
+	return []DependencyCheckResult{
+		{
+			Name: "planned", ChangeType: &added, Version: &version, ManifestPath: &manifest,
+			SourceRepository: &repo, Status: StatusPlanned,
+			Vulnerabilities: []Vulnerability{{ID: "GHSA-high", Severity: High}},
+		},
+		{Name: "no-source", ChangeType: &added, Version: &version, ManifestPath: &manifest, Status: StatusSkippedNoSource},
+		{Name: "removed", ChangeType: &removed, Version: &version, ManifestPath: &manifest, Status: StatusSkippedChangeType},
+	}
+}
+
+// dryRunStatuses are the statuses of the dependencies of dryRunResults by name.
+var dryRunStatuses = map[string]DependencyStatus{
+	"planned":   StatusPlanned,
+	"no-source": StatusSkippedNoSource,
+	"removed":   StatusSkippedChangeType,
+}
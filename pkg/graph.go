@@ -0,0 +1,153 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ossf/scorecard/v4/checker"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sce "github.com/ossf/scorecard/v4/errors"
+)
+
+// graphColors are the fill and stroke colors of the nodes of each change type.
+var graphColors = map[ChangeType]struct{ fill, stroke string }{
+	Added:   {fill: "#dafbe1", stroke: "#1a7f37"},
+	Updated: {fill: "#fff8c5", stroke: "#9a6700"},
+	Removed: {fill: "#ffebe9", stroke: "#cf222e"},
+}
+
+// graphCluster is a manifest and the changes of its dependencies.
+type graphCluster struct {
+	manifest string
+	changes  []ChangeView
+}
+
+// graphClusters groups the changes of a report view by manifest, sorted by manifest.
+func graphClusters(view *ReportView) []graphCluster {
+	byManifest := map[string][]ChangeView{}
+	for _, changes := range [][]ChangeView{view.Added, view.Updated, view.Removed} {
+		for _, cv := range changes {
+			manifest := cv.ManifestPath
+			if manifest == "" {
+				manifest = "unknown manifest"
+			}
+			byManifest[manifest] = append(byManifest[manifest], cv)
+		}
+	}
+	clusters := []graphCluster{}
+	for manifest, changes := range byManifest {
+		clusters = append(clusters, graphCluster{manifest: manifest, changes: changes})
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].manifest < clusters[j].manifest })
+	return clusters
+}
+
+// graphNodeLines are the lines of the label of a node: the name, the version change and the aggregate score,
+// or the status if the score is unknown.
+func graphNodeLines(cv *ChangeView) []string {
+	version := cv.NewVersion
+	switch {
+	case cv.OldVersion != "" && cv.NewVersion != "":
+		version = cv.OldVersion + " → " + cv.NewVersion
+	case cv.NewVersion == "":
+		version = cv.OldVersion
+	}
+	score := fmt.Sprintf("score %.1f", cv.Score)
+	if cv.Score == checker.InconclusiveResultScore {
+		score = string(cv.Status)
+	}
+	lines := []string{cv.Name}
+	for _, l := range []string{version, score} {
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// DependencydiffResultsAsDOT exports dependencydiff results as a Graphviz DOT graph, with a cluster per
+// manifest and a node per dependency change colored by its change type.
+func DependencydiffResultsAsDOT(depdiffResults []DependencyCheckResult,
+	checkDocs docs.Doc, policy *DependencydiffPolicy, writer io.Writer,
+) error {
+	view, err := NewReportView(depdiffResults, checkDocs, policy)
+	if err != nil {
+		return err
+	}
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace
+	var sb strings.Builder
+	sb.WriteString("digraph dependencydiff {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, style=\"rounded,filled\"];\n")
+	node := 0
+	for i, cluster := range graphClusters(view) {
+		sb.WriteString(fmt.Sprintf("  subgraph cluster_%d {\n", i))
+		sb.WriteString(fmt.Sprintf("    label=\"%s\";\n", escape(cluster.manifest)))
+		for j := range cluster.changes {
+			cv := &cluster.changes[j]
+			lines := graphNodeLines(cv)
+			for k := range lines {
+				lines[k] = escape(lines[k])
+			}
+			colors := graphColors[cv.ChangeType]
+			sb.WriteString(fmt.Sprintf(
+				"    n%d [label=\"%s\", fillcolor=\"%s\", color=\"%s\"];\n",
+				node, strings.Join(lines, `\n`), colors.fill, colors.stroke,
+			))
+			node++
+		}
+		sb.WriteString("  }\n")
+	}
+	sb.WriteString("}\n")
+	if _, err := io.WriteString(writer, sb.String()); err != nil {
+		return sce.WithMessage(sce.ErrScorecardInternal, err.Error())
+	}
+	return nil
+}
+
+// DependencydiffResultsAsMermaid exports dependencydiff results as a Mermaid flowchart, with a subgraph per
+// manifest and a node per dependency change colored by its change type. The flowchart can be embedded in
+// markdown rendered by GitHub in a mermaid code block.
+func DependencydiffResultsAsMermaid(depdiffResults []DependencyCheckResult,
+	checkDocs docs.Doc, policy *DependencydiffPolicy, writer io.Writer,
+) error {
+	view, err := NewReportView(depdiffResults, checkDocs, policy)
+	if err != nil {
+		return err
+	}
+	// Mermaid labels are quoted strings, in which entity codes escape the special characters. The # of the
+	// entity codes is escaped as well, so that names which look like entity codes are kept as they are.
+	escape := strings.NewReplacer("#", "#35;", `"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", " ").Replace
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	classes := map[ChangeType][]string{}
+	node := 0
+	for i, cluster := range graphClusters(view) {
+		sb.WriteString(fmt.Sprintf("  subgraph m%d[\"%s\"]\n", i, escape(cluster.manifest)))
+		for j := range cluster.changes {
+			cv := &cluster.changes[j]
+			lines := graphNodeLines(cv)
+			for k := range lines {
+				lines[k] = escape(lines[k])
+			}
+			id := fmt.Sprintf("n%d", node)
+			sb.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", id, strings.Join(lines, "<br/>")))
+			classes[cv.ChangeType] = append(classes[cv.ChangeType], id)
+			node++
+		}
+		sb.WriteString("  end\n")
+	}
+	for _, ct := range []ChangeType{Added, Updated, Removed} {
+		colors := graphColors[ct]
+		sb.WriteString(fmt.Sprintf("  classDef %s fill:%s,stroke:%s\n", ct, colors.fill, colors.stroke))
+		if len(classes[ct]) > 0 {
+			sb.WriteString(fmt.Sprintf("  class %s %s\n", strings.Join(classes[ct], ","), ct))
+		}
+	}
+	if _, err := io.WriteString(writer, sb.String()); err != nil {
+		return sce.WithMessage(sce.ErrScorecardInternal, err.Error())
+	}
+	return nil
+}
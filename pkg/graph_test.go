@@ -0,0 +1,154 @@
+package pkg
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+)
+
+// graphNode is a node of a rendered graph: the change type of its color and the lines of its label.
+type graphNode struct {
+	changeType ChangeType
+	label      []string
+}
+
+var (
+	dotCluster = regexp.MustCompile(`^    label="(.*)";$`)
+	dotNode    = regexp.MustCompile(`^    n\d+ \[label="(.*)", fillcolor="(#\w+)", color="(#\w+)"\];$`)
+
+	mermaidCluster = regexp.MustCompile(`^  subgraph m\d+\["(.*)"\]$`)
+	mermaidNode    = regexp.MustCompile(`^    (n\d+)\["(.*)"\]$`)
+	mermaidClass   = regexp.MustCompile(`^  class ([\w,]+) (\w+)$`)
+)
+
+// parseDOT parses the nodes of a DOT graph by the label of their cluster.
+func parseDOT(t *testing.T, graph string) map[string][]graphNode {
+	t.Helper()
+	clusters := map[string][]graphNode{}
+	cluster := ""
+	for _, line := range strings.Split(graph, "\n") {
+		if m := dotCluster.FindStringSubmatch(line); m != nil {
+			cluster = m[1]
+			continue
+		}
+		m := dotNode.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		label, err := strconv.Unquote(`"` + m[1] + `"`)
+		if err != nil {
+			t.Fatalf("invalid label in %s: %v", line, err)
+		}
+		node := graphNode{label: strings.Split(label, "\n")}
+		for ct, colors := range graphColors {
+			if colors.fill == m[2] && colors.stroke == m[3] {
+				node.changeType = ct
+			}
+		}
+		clusters[cluster] = append(clusters[cluster], node)
+	}
+	return clusters
+}
+
+// parseMermaid parses the nodes of a Mermaid flowchart by the label of their subgraph.
+func parseMermaid(t *testing.T, graph string) map[string][]graphNode {
+	t.Helper()
+	unescape := strings.NewReplacer("#35;", "#", "#quot;", `"`, "#lt;", "<", "#gt;", ">").Replace
+	nodes := map[string]*graphNode{}
+	ids := map[string][]string{}
+	cluster := ""
+	for _, line := range strings.Split(graph, "\n") {
+		if m := mermaidCluster.FindStringSubmatch(line); m != nil {
+			cluster = unescape(m[1])
+		} else if m := mermaidNode.FindStringSubmatch(line); m != nil {
+			nodes[m[1]] = &graphNode{label: strings.Split(unescape(m[2]), "<br/>")}
+			ids[cluster] = append(ids[cluster], m[1])
+		} else if m := mermaidClass.FindStringSubmatch(line); m != nil {
+			for _, id := range strings.Split(m[1], ",") {
+				node, found := nodes[id]
+				if !found {
+					t.Fatalf("class of unknown node %s", id)
+				}
+				node.changeType = ChangeType(m[2])
+			}
+		}
+	}
+	clusters := map[string][]graphNode{}
+	for cluster, clusterIDs := range ids {
+		for _, id := range clusterIDs {
+			clusters[cluster] = append(clusters[cluster], *nodes[id])
+		}
+	}
+	return clusters
+}
+
+func TestDependencydiffResultsAsGraph(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	results := readTestResults(t)
+	want := map[string][]graphNode{
+		"go.mod": {
+			{changeType: Updated, label: []string{"lib", "1.0.0 → 2.0.0", "score 9.0"}},
+		},
+		"package.json": {
+			{changeType: Added, label: []string{"vulnerable", "1.0.0", "score 0.0"}},
+			{changeType: Added, label: []string{"unreachable", "1.0.0", "failed"}},
+			{changeType: Added, label: []string{`=HYPERLINK("<script>")`, "1.0.0", "skipped-no-source"}},
+			{changeType: Removed, label: []string{"gone", "1.0.0", "skipped-change-type"}},
+		},
+	}
+	//nolint
+	tests := []struct {
+		name   string
+		render func([]DependencyCheckResult, docs.Doc, *DependencydiffPolicy, io.Writer) error
+		parse  func(*testing.T, string) map[string][]graphNode
+	}{
+		{name: "dot", render: DependencydiffResultsAsDOT, parse: parseDOT},
+		{name: "mermaid", render: DependencydiffResultsAsMermaid, parse: parseMermaid},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := tt.render(results, checkDocs, nil, &buf); err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if got := tt.parse(t, buf.String()); !reflect.DeepEqual(got, want) {
+				t.Errorf("got nodes %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestDependencydiffResultsAsMermaid_EntityCodes(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	added, version, manifest := Added, "1.0.0", "#lt;dir#gt;/package.json"
+	results := []DependencyCheckResult{
+		{Name: "#quot;name#quot;", ChangeType: &added, Version: &version, ManifestPath: &manifest},
+	}
+	var buf bytes.Buffer
+	if err := DependencydiffResultsAsMermaid(results, checkDocs, nil, &buf); err != nil {
+		t.Fatalf("DependencydiffResultsAsMermaid: %v", err)
+	}
+	// Names which look like entity codes are not rendered as the characters of the codes.
+	want := map[string][]graphNode{
+		manifest: {{changeType: Added, label: []string{"#quot;name#quot;", "1.0.0"}}},
+	}
+	if got := parseMermaid(t, buf.String()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got nodes %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+
+	"github.com/ossf/scorecard/v4/checker"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sce "github.com/ossf/scorecard/v4/errors"
+	"github.com/ossf/scorecard/v4/log"
+)
+
+//go:embed report.html.tmpl
+var htmlReportTemplate string
+
+var errOddDictArguments = errors.New("dict expects pairs of keys and values")
+
+// reportSection is a section of the HTML report listing the changes of a change type.
+type reportSection struct {
+	Title   string
+	Changes []ChangeView
+}
+
+// htmlReportFuncs are the functions of the HTML report in addition to TemplateFuncs.
+var htmlReportFuncs = map[string]interface{}{
+	"sections": func(view *ReportView) []reportSection {
+		sections := []reportSection{}
+		for _, s := range []reportSection{
+			{Title: "Added dependencies", Changes: view.Added},
+			{Title: "Updated dependencies", Changes: view.Updated},
+			{Title: "Removed dependencies", Changes: view.Removed},
+		} {
+			if len(s.Changes) > 0 {
+				sections = append(sections, s)
+			}
+		}
+		return sections
+	},
+	"scoreClass": func(score float64) string {
+		return scoreClass(score)
+	},
+	"checkClass": func(c *checker.CheckResult) string {
+		return scoreClass(float64(c.Score))
+	},
+	"dict": func(pairs ...interface{}) (map[string]interface{}, error) {
+		if len(pairs)%2 != 0 {
+			return nil, errOddDictArguments
+		}
+		dict := map[string]interface{}{}
+		for i := 0; i < len(pairs); i += 2 {
+			dict[fmt.Sprint(pairs[i])] = pairs[i+1]
+		}
+		return dict, nil
+	},
+}
+
+// scoreClass is the class of the badge of a score, colored by risk.
+func scoreClass(score float64) string {
+	switch {
+	case score == checker.InconclusiveResultScore:
+		return "unknown"
+	case score >= 7:
+		return "high"
+	case score >= 4:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// DependencydiffResultsAsHTML exports dependencydiff results as a self-contained HTML report, which has no
+// external assets so that it can be opened offline, e.g. as a CI artifact. Each change type gets a table
+// sortable by any column, with expandable check details and vulnerabilities per dependency.
+func DependencydiffResultsAsHTML(depdiffResults []DependencyCheckResult,
+	logLevel log.Level, checkDocs docs.Doc, policy *DependencydiffPolicy, writer io.Writer,
+) error {
+	view, err := NewReportView(depdiffResults, checkDocs, policy)
+	if err != nil {
+		return err
+	}
+	tmpl, err := htmltemplate.New("report").
+		Funcs(TemplateFuncs(checkDocs, logLevel)).
+		Funcs(htmlReportFuncs).
+		Parse(htmlReportTemplate)
+	if err != nil {
+		return sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("template.Parse: %v", err))
+	}
+	if err := tmpl.Execute(writer, view); err != nil {
+		return sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("template.Execute: %v", err))
+	}
+	return nil
+}
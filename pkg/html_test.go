@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	"github.com/ossf/scorecard/v4/log"
+)
+
+// htmlReport is the structure of a parsed HTML report.
+type htmlReport struct {
+	verdict  string
+	headings []string
+	// tables are the rows of the table body following each heading, as the text of their cells.
+	tables map[string][][]string
+	// external are the elements loading external assets.
+	external []string
+}
+
+// parseHTMLReport parses an HTML report with the HTML mode of the XML decoder, collapsing the whitespace of
+// the text of the elements.
+func parseHTMLReport(t *testing.T, report []byte) *htmlReport {
+	t.Helper()
+	decoder := xml.NewDecoder(bytes.NewReader(report))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+	parsed := &htmlReport{tables: map[string][][]string{}}
+	var text *strings.Builder
+	var row []string
+	heading, inBody := "", false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return parsed
+		}
+		if err != nil {
+			t.Fatalf("Token: %v", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			for _, attr := range token.Attr {
+				if token.Name.Local == "link" || attr.Name.Local == "src" {
+					parsed.external = append(parsed.external, token.Name.Local)
+				}
+			}
+			switch {
+			case token.Name.Local == "h2", token.Name.Local == "td",
+				token.Name.Local == "p" && len(token.Attr) > 0 && strings.HasPrefix(token.Attr[0].Value, "verdict"):
+				text = &strings.Builder{}
+			case token.Name.Local == "tbody":
+				inBody = true
+			case token.Name.Local == "tr" && inBody:
+				row = []string{}
+			}
+		case xml.EndElement:
+			switch {
+			case token.Name.Local == "h2":
+				heading = strings.Join(strings.Fields(text.String()), " ")
+				parsed.headings = append(parsed.headings, heading)
+			case token.Name.Local == "p" && text != nil && parsed.verdict == "":
+				parsed.verdict = strings.Join(strings.Fields(text.String()), " ")
+			case token.Name.Local == "td":
+				row = append(row, strings.Join(strings.Fields(text.String()), " "))
+			case token.Name.Local == "tbody":
+				inBody = false
+			case token.Name.Local == "tr" && inBody:
+				parsed.tables[heading] = append(parsed.tables[heading], row)
+			default:
+				continue
+			}
+			text = nil
+		case xml.CharData:
+			if text != nil {
+				text.Write(token)
+			}
+		}
+	}
+}
+
+func TestDependencydiffResultsAsHTML(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	var buf bytes.Buffer
+	err = DependencydiffResultsAsHTML(
+		readTestResults(t), log.DefaultLevel, checkDocs, &DependencydiffPolicy{FailOnSeverity: High}, &buf,
+	)
+	if err != nil {
+		t.Fatalf("DependencydiffResultsAsHTML: %v", err)
+	}
+	got := parseHTMLReport(t, buf.Bytes())
+	if got.verdict != "Policy verdict: fail" {
+		t.Errorf("got verdict %q, want fail", got.verdict)
+	}
+	wantHeadings := []string{
+		"Added dependencies (3)", "Updated dependencies (1)", "Removed dependencies (1)",
+		"Introduced vulnerabilities", "Fixed vulnerabilities", "Policy violations",
+	}
+	if !reflect.DeepEqual(got.headings, wantHeadings) {
+		t.Errorf("got headings %q, want %q", got.headings, wantHeadings)
+	}
+	// The columns are the dependency, ecosystem, version, status, score, Code-Review and License checks,
+	// vulnerabilities, license and details.
+	wantTables := map[string][][]string{
+		"Added dependencies (3)": {
+			{
+				"vulnerable", "npm", "1.0.0", "evaluated", "0.0", "-", "0", "1 (+1)", "GPL-3.0-only",
+				"1 checks, 1 vulnerabilities License 0: license file not detected Warn: license file not found " +
+					"HIGH GHSA-high prototype pollution (fixed in 1.0.1) " +
+					"Upgrade to 1.0.1 to fix the known vulnerabilities.",
+			},
+			{
+				"unreachable", "npm", "1.0.0", "failed", "-", "-", "-", "0", "",
+				"0 checks, 0 vulnerabilities Scorecard failed: repo unreachable: not found",
+			},
+			{`=HYPERLINK("<script>")`, "npm", "1.0.0", "skipped-no-source", "-", "-", "-", "0", "", ""},
+		},
+		"Updated dependencies (1)": {
+			{
+				"lib", "Go", "1.0.0 → 2.0.0", "evaluated", "9.0 (+2.0)", "?", "9", "0 (-1)", "MIT",
+				"2 checks, 0 vulnerabilities License 9: license file detected Code-Review ?: internal error",
+			},
+		},
+		"Removed dependencies (1)": {
+			{"gone", "npm", "1.0.0", "skipped-change-type", "-", "-", "-", "0", "", ""},
+		},
+	}
+	if !reflect.DeepEqual(got.tables, wantTables) {
+		t.Errorf("got tables\n%q\nwant\n%q", got.tables, wantTables)
+	}
+	// The report is self-contained.
+	if len(got.external) > 0 {
+		t.Errorf("report contains external assets: %v", got.external)
+	}
+}
+
+func TestDependencydiffResultsAsHTML_DryRun(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := DependencydiffResultsAsHTML(dryRunResults(), log.DefaultLevel, checkDocs, nil, &buf); err != nil {
+		t.Fatalf("DependencydiffResultsAsHTML: %v", err)
+	}
+	// Every row of the tables is a dependency, whose name and status are in the first and fourth cells.
+	got := map[string]DependencyStatus{}
+	for _, rows := range parseHTMLReport(t, buf.Bytes()).tables {
+		for _, row := range rows {
+			got[row[0]] = DependencyStatus(row[3])
+		}
+	}
+	if !reflect.DeepEqual(got, dryRunStatuses) {
+		t.Errorf("got statuses %v, want %v", got, dryRunStatuses)
+	}
+}
@@ -1,15 +1,32 @@
 package pkg
 
 import (
+	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
+	"github.com/ossf/scorecard/v4/checker"
 	docs "github.com/ossf/scorecard/v4/docs/checks"
 	sce "github.com/ossf/scorecard/v4/errors"
 	"github.com/ossf/scorecard/v4/log"
+	scpkg "github.com/ossf/scorecard/v4/pkg"
 )
 
+// DependencydiffJSONSchemaVersion is the version of the JSON format of dependency-diff results. The major version
+// is bumped on incompatible changes of the format.
+const DependencydiffJSONSchemaVersion = "1.1.0"
+
+// DependencydiffJSONSchema is the JSON schema of the dependency-diff results exported as JSON.
+//
+//go:embed json.dependencydiff.schema
+var DependencydiffJSONSchema string
+
+var errUnsupportedSchemaVersion = errors.New("unsupported schema version")
+
 //nolint
 type jsonCheckResult struct {
 	Name       string
@@ -68,38 +85,89 @@ type JSONScorecardResultV2 struct {
 	Metadata       []string            `json:"metadata"`
 }
 
+type jsonVulnerability struct {
+	Source       string        `json:"source"`
+	ID           string        `json:"id"`
+	Severity     SeverityLevel `json:"severity"`
+	Summary      string        `json:"summary"`
+	URL          string        `json:"url"`
+	FixedVersion string        `json:"fixedVersion,omitempty"`
+}
+
+type jsonFixSuggestion struct {
+	Version   string `json:"version"`
+	SameMajor bool   `json:"sameMajor"`
+}
+
+type jsonError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 // JSONDependencydiffResult exports dependency-diff check results as JSON for new detail format.
 type JSONDependencydiffResult struct {
+	SchemaVersion       string                 `json:"schemaVersion"`
 	ChangeType          *ChangeType            `json:"changeType"`
 	PackageURL          *string                `json:"packageUrl"`
 	SourceRepository    *string                `json:"sourceRepository"`
 	ManifestPath        *string                `json:"manifestPath"`
 	Ecosystem           *string                `json:"ecosystem"`
 	Version             *string                `json:"packageVersion"`
+	License             *string                `json:"license"`
 	JSONScorecardResult *JSONScorecardResultV2 `json:"scorecardResult"`
+	Status              DependencyStatus       `json:"status"`
+	Error               *jsonError             `json:"error,omitempty"`
+	Vulnerabilities     []jsonVulnerability    `json:"vulnerabilities"`
+	FixSuggestion       *jsonFixSuggestion     `json:"fixSuggestion"`
 	Name                string                 `json:"packageName"`
 }
 
 // DependencydiffResultsAsJSON exports dependencydiff results as JSON. This cannot be defined as the OOP-like
 // ScorecardResult.AsJSON since we return a slice of DependencyCheckResult.
+// The aggregate score of each dependency is weighted by the given policy, which can be nil.
 func DependencydiffResultsAsJSON(depdiffResults []DependencyCheckResult,
-	logLevel log.Level, doc docs.Doc, writer io.Writer,
+	logLevel log.Level, doc docs.Doc, policy *DependencydiffPolicy, writer io.Writer,
 ) error {
 	out := []JSONDependencydiffResult{}
 	for _, dr := range depdiffResults {
 		// Copy every DependencydiffResult struct to a JSONDependencydiffResult for exporting as JSON.
 		jsonDepdiff := JSONDependencydiffResult{
+			SchemaVersion:    DependencydiffJSONSchemaVersion,
 			ChangeType:       dr.ChangeType,
 			PackageURL:       dr.PackageURL,
 			SourceRepository: dr.SourceRepository,
 			ManifestPath:     dr.ManifestPath,
 			Ecosystem:        dr.Ecosystem,
 			Version:          dr.Version,
+			License:          dr.License,
+			Status:           dr.Status,
 			Name:             dr.Name,
 		}
+		if err := dr.ScorecardResultWithError.Error; err != nil {
+			jsonDepdiff.Error = &jsonError{
+				Code:    sce.GetName(err),
+				Message: err.Error(),
+			}
+		}
+		for _, v := range dr.Vulnerabilities {
+			jsonDepdiff.Vulnerabilities = append(jsonDepdiff.Vulnerabilities, jsonVulnerability{
+				Source:       v.Source,
+				ID:           v.ID,
+				Severity:     v.Severity,
+				Summary:      v.Title,
+				URL:          v.SourceURL,
+				FixedVersion: v.FixedVersion,
+			})
+		}
+		if dr.FixSuggestion != nil {
+			jsonDepdiff.FixSuggestion = &jsonFixSuggestion{
+				Version:   dr.FixSuggestion.Version,
+				SameMajor: dr.FixSuggestion.SameMajor,
+			}
+		}
 		scResult := dr.ScorecardResultWithError.ScorecardResult
 		if scResult != nil {
-			score, err := scResult.GetAggregateScore(doc)
+			score, err := policy.GetAggregateScore(scResult, doc)
 			if err != nil {
 				return err
 			}
@@ -150,3 +218,140 @@ func DependencydiffResultsAsJSON(depdiffResults []DependencyCheckResult,
 	}
 	return nil
 }
+
+// decodedError is an error decoded from JSON. It keeps the message of the original error, and unwraps to the
+// Scorecard error of the same code so that errors.Is and sce.GetName still work on it.
+type decodedError struct {
+	base    error
+	message string
+}
+
+func (e *decodedError) Error() string {
+	return e.message
+}
+
+func (e *decodedError) Unwrap() error {
+	return e.base
+}
+
+func errorFromJSON(je *jsonError) error {
+	var base error
+	for _, e := range []error{sce.ErrScorecardInternal, sce.ErrRepoUnreachable, sce.ErrorShellParsing} {
+		if sce.GetName(e) == je.Code {
+			base = e
+		}
+	}
+	return &decodedError{base: base, message: je.Message}
+}
+
+// detailFromString parses a detail formatted by DetailToString. The location and remediation of the detail
+// are kept in its text, so that it is formatted back to the same string.
+func detailFromString(s string) checker.CheckDetail {
+	detail := checker.CheckDetail{Type: checker.DetailInfo, Msg: checker.LogMessage{Text: s}}
+	prefix, text, found := strings.Cut(s, ": ")
+	if !found {
+		return detail
+	}
+	for _, t := range []checker.DetailType{checker.DetailInfo, checker.DetailWarn, checker.DetailDebug} {
+		if typeToString(t) == prefix {
+			detail.Type = t
+			detail.Msg.Text = text
+		}
+	}
+	return detail
+}
+
+func scorecardResultFromJSON(jr *JSONScorecardResultV2) (*scpkg.ScorecardResult, error) {
+	result := &scpkg.ScorecardResult{
+		Repo: scpkg.RepoInfo{
+			Name:      jr.Repo.Name,
+			CommitSHA: jr.Repo.Commit,
+		},
+		Scorecard: scpkg.ScorecardInfo{
+			Version:   jr.Scorecard.Version,
+			CommitSHA: jr.Scorecard.Commit,
+		},
+		Metadata: jr.Metadata,
+	}
+	if jr.Date != "" {
+		date, err := time.Parse("2006-01-02", jr.Date)
+		if err != nil {
+			return nil, sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("time.Parse: %v", err))
+		}
+		result.Date = date
+	}
+	for _, c := range jr.Checks {
+		check := checker.CheckResult{
+			Name:   c.Name,
+			Score:  c.Score,
+			Reason: c.Reason,
+		}
+		for _, d := range c.Details {
+			check.Details = append(check.Details, detailFromString(d))
+		}
+		result.Checks = append(result.Checks, check)
+	}
+	return result, nil
+}
+
+// DependencydiffResultsFromJSON decodes dependencydiff results exported by DependencydiffResultsAsJSON, so that
+// saved results can be rendered again or compared without running the Scorecard checks. Results of a different
+// major schema version are rejected, while results without a schema version are assumed to be compatible.
+// Check details are kept as formatted, and the aggregate scores and check docs are not decoded as they are
+// derived from the checks.
+func DependencydiffResultsFromJSON(reader io.Reader) ([]DependencyCheckResult, error) {
+	var in []JSONDependencydiffResult
+	if err := json.NewDecoder(reader).Decode(&in); err != nil {
+		return nil, sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("decoder.Decode: %v", err))
+	}
+	wantMajor, _, _ := strings.Cut(DependencydiffJSONSchemaVersion, ".")
+	results := []DependencyCheckResult{}
+	for i := range in {
+		jd := &in[i]
+		if jd.SchemaVersion != "" {
+			if major, _, _ := strings.Cut(jd.SchemaVersion, "."); major != wantMajor {
+				return nil, fmt.Errorf("%w: %s of %s, expected %s",
+					errUnsupportedSchemaVersion, jd.SchemaVersion, jd.Name, DependencydiffJSONSchemaVersion)
+			}
+		}
+		dr := DependencyCheckResult{
+			ChangeType:       jd.ChangeType,
+			PackageURL:       jd.PackageURL,
+			SourceRepository: jd.SourceRepository,
+			ManifestPath:     jd.ManifestPath,
+			Ecosystem:        jd.Ecosystem,
+			Version:          jd.Version,
+			License:          jd.License,
+			Status:           jd.Status,
+			Name:             jd.Name,
+		}
+		if jd.Error != nil {
+			dr.ScorecardResultWithError.Error = errorFromJSON(jd.Error)
+		}
+		if jd.JSONScorecardResult != nil {
+			scResult, err := scorecardResultFromJSON(jd.JSONScorecardResult)
+			if err != nil {
+				return nil, err
+			}
+			dr.ScorecardResultWithError.ScorecardResult = scResult
+		}
+		for _, v := range jd.Vulnerabilities {
+			dr.Vulnerabilities = append(dr.Vulnerabilities, Vulnerability{
+				Source:       v.Source,
+				ID:           v.ID,
+				Severity:     v.Severity,
+				Title:        v.Summary,
+				SourceURL:    v.URL,
+				FixedVersion: v.FixedVersion,
+			})
+		}
+		if jd.FixSuggestion != nil {
+			dr.FixSuggestion = &FixSuggestion{
+				Version:   jd.FixSuggestion.Version,
+				SameMajor: jd.FixSuggestion.SameMajor,
+			}
+		}
+		results = append(results, dr)
+	}
+	return results, nil
+}
@@ -0,0 +1,217 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ossf/scorecard/v4/checker"
+	"github.com/ossf/scorecard/v4/checks"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sce "github.com/ossf/scorecard/v4/errors"
+	"github.com/ossf/scorecard/v4/log"
+	scpkg "github.com/ossf/scorecard/v4/pkg"
+)
+
+func TestDependencydiffResultsJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	added, updated := Added, Updated
+	version, manifest, license := "1.0.0", "go.mod", "MIT"
+	results := []DependencyCheckResult{
+		{
+			Name: "scored", ChangeType: &added, Version: &version, ManifestPath: &manifest, License: &license,
+			Status: StatusEvaluated,
+			ScorecardResultWithError: ScorecardResultWithError{
+				ScorecardResult: &scpkg.ScorecardResult{
+					Repo:      scpkg.RepoInfo{Name: "github.com/owner/scored", CommitSHA: "abc"},
+					Scorecard: scpkg.ScorecardInfo{Version: "v4.4.0", CommitSHA: "def"},
+					Date:      time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+					Checks: []checker.CheckResult{
+						{
+							Name: checks.CheckLicense, Score: 10, Reason: "license file detected",
+							Details: []checker.CheckDetail{
+								{Type: checker.DetailInfo, Msg: checker.LogMessage{Text: "license file: LICENSE"}},
+								{Type: checker.DetailWarn, Msg: checker.LogMessage{Text: "no spdx header"}},
+							},
+						},
+					},
+					Metadata: []string{},
+				},
+			},
+		},
+		{
+			Name: "vulnerable", ChangeType: &updated, Version: &version, Status: StatusFailed,
+			ScorecardResultWithError: ScorecardResultWithError{
+				Error: sce.WithMessage(sce.ErrRepoUnreachable, "not found"),
+			},
+			Vulnerabilities: []Vulnerability{
+				{Source: "GHSA", ID: "GHSA-high", Severity: High, Title: "bad", SourceURL: "https://x", FixedVersion: "1.0.1"},
+			},
+			FixSuggestion: &FixSuggestion{Version: "1.0.1", SameMajor: true},
+		},
+	}
+	var buf bytes.Buffer
+	if err := DependencydiffResultsAsJSON(results, log.DefaultLevel, checkDocs, nil, &buf); err != nil {
+		t.Fatalf("DependencydiffResultsAsJSON: %v", err)
+	}
+	got, err := DependencydiffResultsFromJSON(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("DependencydiffResultsFromJSON: %v", err)
+	}
+	if len(got) != len(results) {
+		t.Fatalf("got %d results, want %d", len(got), len(results))
+	}
+	// The decoded error is compared by its message and code.
+	gotErr, wantErr := got[1].ScorecardResultWithError.Error, results[1].ScorecardResultWithError.Error
+	if gotErr == nil || gotErr.Error() != wantErr.Error() || !errors.Is(gotErr, sce.ErrRepoUnreachable) {
+		t.Errorf("got error %v, want %v", gotErr, wantErr)
+	}
+	got[1].ScorecardResultWithError.Error = wantErr
+	if !reflect.DeepEqual(got, results) {
+		t.Errorf("got %+v, want %+v", got, results)
+	}
+	// Encoding the decoded results gives the same JSON.
+	var again bytes.Buffer
+	if err := DependencydiffResultsAsJSON(got, log.DefaultLevel, checkDocs, nil, &again); err != nil {
+		t.Fatalf("DependencydiffResultsAsJSON: %v", err)
+	}
+	if again.String() != buf.String() {
+		t.Errorf("got %s, want %s", again.String(), buf.String())
+	}
+}
+
+func TestDependencydiffResultsFromJSON(t *testing.T) {
+	t.Parallel()
+	//nolint
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr error
+	}{
+		{
+			name:  "current version",
+			input: `[{"schemaVersion": "1.2.0", "packageName": "a"}]`,
+			want:  1,
+		},
+		{
+			name:  "no version",
+			input: `[{"packageName": "a"}, {"packageName": "b"}]`,
+			want:  2,
+		},
+		{
+			name:    "unsupported version",
+			input:   `[{"schemaVersion": "2.0.0", "packageName": "a"}]`,
+			wantErr: errUnsupportedSchemaVersion,
+		},
+		{
+			name:    "invalid json",
+			input:   `{`,
+			wantErr: sce.ErrScorecardInternal,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := DependencydiffResultsFromJSON(strings.NewReader(tt.input))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("got %d results, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestDependencydiffJSONSchema(t *testing.T) {
+	t.Parallel()
+	var schema struct {
+		Items struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(DependencydiffJSONSchema), &schema); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	// Every field of the JSON output is described by the schema.
+	typ := reflect.TypeOf(JSONDependencydiffResult{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if _, ok := schema.Items.Properties[name]; !ok {
+			t.Errorf("field %s is not in the schema", name)
+		}
+	}
+	for _, name := range schema.Items.Required {
+		if _, ok := schema.Items.Properties[name]; !ok {
+			t.Errorf("required field %s is not a property", name)
+		}
+	}
+}
+
+func TestDependencydiffResultsAsJSON_Status(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	//nolint
+	tests := []struct {
+		name      string
+		result    DependencyCheckResult
+		wantError *jsonError
+	}{
+		{
+			name:   "skipped",
+			result: DependencyCheckResult{Name: "skipped", Status: StatusSkippedNoSource},
+		},
+		{
+			name: "failed",
+			result: DependencyCheckResult{
+				Name:   "failed",
+				Status: StatusFailed,
+				ScorecardResultWithError: ScorecardResultWithError{
+					Error: sce.WithMessage(sce.ErrRepoUnreachable, "not found"),
+				},
+			},
+			wantError: &jsonError{Code: "ErrRepoUnreachable", Message: "repo unreachable: not found"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			err := DependencydiffResultsAsJSON([]DependencyCheckResult{tt.result}, log.DefaultLevel, checkDocs, nil, &buf)
+			if err != nil {
+				t.Fatalf("DependencydiffResultsAsJSON: %v", err)
+			}
+			var got []JSONDependencydiffResult
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %d results, want 1", len(got))
+			}
+			if got[0].Status != tt.result.Status {
+				t.Errorf("got status %s, want %s", got[0].Status, tt.result.Status)
+			}
+			switch {
+			case tt.wantError == nil && got[0].Error != nil:
+				t.Errorf("got error %+v, want none", *got[0].Error)
+			case tt.wantError != nil && (got[0].Error == nil || *got[0].Error != *tt.wantError):
+				t.Errorf("got error %+v, want %+v", got[0].Error, *tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package pkg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ossf/scorecard/v4/checker"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sce "github.com/ossf/scorecard/v4/errors"
+)
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+	Skipped   *junitSkipped  `xml:"skipped"`
+	SystemOut string         `xml:"system-out,omitempty"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+// DependencydiffResultsAsJUnit exports dependencydiff results as JUnit XML, with a test suite per change type
+// and a test case per dependency. Each policy violation and Scorecard failure of a dependency is a failure of
+// its test case, detailed with the check reasons, and dependencies the checks were not run on are skipped.
+func DependencydiffResultsAsJUnit(depdiffResults []DependencyCheckResult,
+	checkDocs docs.Doc, policy *DependencydiffPolicy, writer io.Writer,
+) error {
+	evaluation, err := EvaluateDependencydiffPolicy(depdiffResults, policy, checkDocs)
+	if err != nil {
+		return err
+	}
+	violations := map[*DependencyCheckResult][]PolicyViolation{}
+	for _, v := range evaluation.Violations {
+		violations[v.Dependency] = append(violations[v.Dependency], v)
+	}
+	suites := map[ChangeType]*junitTestSuite{}
+	out := junitTestSuites{Name: "Dependency-diff"}
+	for _, ct := range []ChangeType{Added, Updated, Removed} {
+		out.TestSuites = append(out.TestSuites, junitTestSuite{Name: string(ct)})
+	}
+	for i := range out.TestSuites {
+		suites[ChangeType(out.TestSuites[i].Name)] = &out.TestSuites[i]
+	}
+	for _, c := range PairDependencyChanges(depdiffResults) {
+		d := c.New
+		if d == nil {
+			d = c.Old
+		}
+		reasons := checkReasons(d)
+		tc := junitTestCase{
+			Name:      fmt.Sprintf("%s @ %s", d.Name, derefString(d.Version)),
+			ClassName: derefString(d.Ecosystem),
+			SystemOut: reasons,
+		}
+		for _, dep := range []*DependencyCheckResult{c.Old, c.New} {
+			if dep == nil {
+				continue
+			}
+			for _, v := range violations[dep] {
+				tc.Failures = append(tc.Failures, junitFailure{
+					Message: fmt.Sprintf("%s: %s %s", v.Verdict, dep.Name, v.Message),
+					Type:    v.Rule,
+					Text:    reasons,
+				})
+			}
+		}
+		if err := d.ScorecardResultWithError.Error; err != nil {
+			tc.Failures = append(tc.Failures, junitFailure{
+				Message: err.Error(),
+				Type:    sce.GetName(err),
+			})
+		}
+		suite := suites[c.ChangeType]
+		if len(tc.Failures) == 0 && d.ScorecardResultWithError.ScorecardResult == nil {
+			tc.Skipped = &junitSkipped{Message: string(d.Status)}
+			suite.Skipped++
+		}
+		if len(tc.Failures) > 0 {
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	for _, suite := range out.TestSuites {
+		out.Tests += suite.Tests
+		out.Failures += suite.Failures
+		out.Skipped += suite.Skipped
+	}
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return sce.WithMessage(sce.ErrScorecardInternal, err.Error())
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("encoder.Encode: %v", err))
+	}
+	if _, err := io.WriteString(writer, "\n"); err != nil {
+		return sce.WithMessage(sce.ErrScorecardInternal, err.Error())
+	}
+	return nil
+}
+
+// checkReasons lists the score and reason of each check run on a dependency.
+func checkReasons(d *DependencyCheckResult) string {
+	scResult := d.ScorecardResultWithError.ScorecardResult
+	if scResult == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, c := range scResult.Checks {
+		score := fmt.Sprintf("%d", c.Score)
+		if c.Score == checker.InconclusiveResultScore {
+			score = "?"
+		}
+		sb.WriteString(fmt.Sprintf("%s: %s / %d: %s\n", c.Name, score, checker.MaxResultScore, c.Reason))
+	}
+	return sb.String()
+}
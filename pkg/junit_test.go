@@ -0,0 +1,76 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+)
+
+func TestDependencydiffResultsAsJUnit(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	var buf bytes.Buffer
+	err = DependencydiffResultsAsJUnit(readTestResults(t), checkDocs, &DependencydiffPolicy{FailOnSeverity: High}, &buf)
+	if err != nil {
+		t.Fatalf("DependencydiffResultsAsJUnit: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("got no XML header in %s", buf.String())
+	}
+	var got junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("xml.Unmarshal: %v", err)
+	}
+	licenseReason := "License: 0 / 10: license file not detected\n"
+	want := junitTestSuites{
+		XMLName: xml.Name{Local: "testsuites"}, Name: "Dependency-diff", Tests: 5, Failures: 2, Skipped: 2,
+		TestSuites: []junitTestSuite{
+			{
+				Name: string(Added), Tests: 3, Failures: 2, Skipped: 1,
+				TestCases: []junitTestCase{
+					{
+						Name: "vulnerable @ 1.0.0", ClassName: "npm",
+						Failures: []junitFailure{{
+							Message: "fail: vulnerable introduces HIGH vulnerability GHSA-high: prototype pollution",
+							Type:    RuleVulnerability,
+							Text:    licenseReason,
+						}},
+						SystemOut: licenseReason,
+					},
+					{
+						Name: "unreachable @ 1.0.0", ClassName: "npm",
+						Failures: []junitFailure{{Message: "repo unreachable: not found", Type: "ErrRepoUnreachable"}},
+					},
+					{
+						Name: `=HYPERLINK("<script>") @ 1.0.0`, ClassName: "npm",
+						Skipped: &junitSkipped{Message: string(StatusSkippedNoSource)},
+					},
+				},
+			},
+			{
+				Name: string(Updated), Tests: 1,
+				TestCases: []junitTestCase{{
+					Name: "lib @ 2.0.0", ClassName: "Go",
+					SystemOut: "License: 9 / 10: license file detected\nCode-Review: ? / 10: internal error\n",
+				}},
+			},
+			{
+				Name: string(Removed), Tests: 1, Skipped: 1,
+				TestCases: []junitTestCase{{
+					Name: "gone @ 1.0.0", ClassName: "npm",
+					Skipped: &junitSkipped{Message: string(StatusSkippedChangeType)},
+				}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got test suites\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,272 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errInvalidLicenseExpression = errors.New("invalid SPDX license expression")
+
+// LicensePolicy is the policy on the declared SPDX licenses of added and updated dependencies.
+type LicensePolicy struct {
+	// Allow is a list of allowed SPDX licenses. If it is empty, all the licenses not denied are allowed.
+	// Licenses are simple SPDX expressions: a license ID, optionally with an exception, such as
+	// "GPL-2.0-only WITH Classpath-exception-2.0". A license ID without an exception also matches the license
+	// with any exception, and the most specific license matching a dependency decides whether it is allowed.
+	Allow []string `yaml:"allow"`
+
+	// Deny is a list of denied SPDX licenses, such as AGPL-3.0-only, in the same form as Allow.
+	Deny []string `yaml:"deny"`
+
+	// WarnOnUnknown gives a warning for dependencies without a known license.
+	WarnOnUnknown bool `yaml:"warn_on_unknown"`
+}
+
+// LicenseChange is a change of the declared license of an updated dependency.
+type LicenseChange struct {
+	// Change is the updated dependency.
+	Change DependencyChange
+
+	// Old is the license at BASE, empty if unknown.
+	Old string
+
+	// New is the license at HEAD, empty if unknown.
+	New string
+}
+
+// GetLicenseChanges finds the updated dependencies of which the declared license changed.
+func GetLicenseChanges(changes []DependencyChange) []LicenseChange {
+	licenseChanges := []LicenseChange{}
+	for _, c := range changes {
+		if c.Old == nil || c.New == nil {
+			continue
+		}
+		oldLicense, newLicense := knownLicense(c.Old.License), knownLicense(c.New.License)
+		if oldLicense != newLicense {
+			licenseChanges = append(licenseChanges, LicenseChange{Change: c, Old: oldLicense, New: newLicense})
+		}
+	}
+	return licenseChanges
+}
+
+// knownLicense returns the declared license, or an empty string if it is unknown.
+func knownLicense(license *string) string {
+	if license == nil {
+		return ""
+	}
+	l := strings.TrimSpace(*license)
+	// NOASSERTION and NONE are used by SPDX for licenses which cannot be determined or are not declared.
+	switch strings.ToUpper(l) {
+	case "NOASSERTION", "NONE":
+		return ""
+	default:
+		return l
+	}
+}
+
+func (p *LicensePolicy) validate() error {
+	for _, license := range append(append([]string{}, p.Allow...), p.Deny...) {
+		if expr, err := parseLicenseExpression(license); err != nil || expr.operator != "" {
+			return fmt.Errorf("%w: license %q is not a license ID with an optional exception",
+				errInvalidLicenseExpression, license)
+		}
+	}
+	return nil
+}
+
+// License matches, from the least to the most specific.
+const (
+	noLicenseMatch = iota
+	licenseIDMatch
+	licenseExceptionMatch
+)
+
+// isPermitted checks whether a simple license expression is permitted by the policy. The most specific license
+// of the policy matching it decides, a denied license winning over an allowed one as specific. A license which
+// matches none of them is permitted only if there is no allow list.
+func (p *LicensePolicy) isPermitted(license *licenseExpression) bool {
+	allowed, denied := bestLicenseMatch(p.Allow, license), bestLicenseMatch(p.Deny, license)
+	if denied != noLicenseMatch && denied >= allowed {
+		return false
+	}
+	return allowed != noLicenseMatch || len(p.Allow) == 0
+}
+
+// bestLicenseMatch returns the most specific match of a simple license expression among the licenses of a list.
+func bestLicenseMatch(licenses []string, license *licenseExpression) int {
+	best := noLicenseMatch
+	for _, l := range licenses {
+		// The licenses of the policy are validated as simple expressions.
+		expr, err := parseLicenseExpression(l)
+		if err != nil || !strings.EqualFold(expr.id, license.id) {
+			continue
+		}
+		switch {
+		case expr.exception == "":
+			if best < licenseIDMatch {
+				best = licenseIDMatch
+			}
+		case strings.EqualFold(expr.exception, license.exception):
+			best = licenseExceptionMatch
+		}
+	}
+	return best
+}
+
+// evaluate evaluates the policy on a declared license, returning nil if the license complies with the policy.
+func (p *LicensePolicy) evaluate(license string) *PolicyViolation {
+	if license == "" {
+		if p.WarnOnUnknown {
+			return &PolicyViolation{Rule: RuleLicense, Message: "has an unknown license", Verdict: VerdictWarn}
+		}
+		return nil
+	}
+	expr, err := parseLicenseExpression(license)
+	if err != nil {
+		if p.WarnOnUnknown {
+			return &PolicyViolation{
+				Rule:    RuleLicense,
+				Message: fmt.Sprintf("has an unrecognized license %q", license),
+				Verdict: VerdictWarn,
+			}
+		}
+		return nil
+	}
+	if !expr.satisfiedBy(p.isPermitted) {
+		return &PolicyViolation{
+			Rule:    RuleLicense,
+			Message: fmt.Sprintf("has license %s which is not allowed by the policy", license),
+			Verdict: VerdictFail,
+		}
+	}
+	return nil
+}
+
+// licenseExpression is a parsed SPDX license expression, see https://spdx.github.io/spdx-spec/v2.3/SPDX-license-expressions/.
+type licenseExpression struct {
+	// id is the license ID of a simple expression, with the "+" operator removed.
+	id string
+
+	// exception is the license exception of a simple expression, given by the WITH operator, if any.
+	exception string
+
+	// operator is either AND or OR for a compound expression.
+	operator string
+
+	operands []*licenseExpression
+}
+
+// satisfiedBy checks whether the expression can be satisfied using the permitted licenses only.
+// A disjunctive (OR) expression needs one of its operands to be satisfied while a conjunctive (AND) one needs all.
+func (e *licenseExpression) satisfiedBy(permitted func(license *licenseExpression) bool) bool {
+	switch e.operator {
+	case "OR":
+		for _, o := range e.operands {
+			if o.satisfiedBy(permitted) {
+				return true
+			}
+		}
+		return false
+	case "AND":
+		for _, o := range e.operands {
+			if !o.satisfiedBy(permitted) {
+				return false
+			}
+		}
+		return true
+	default:
+		return permitted(e)
+	}
+}
+
+// parseLicenseExpression parses an SPDX license expression. The "+" operator is dropped since the policy
+// applies to license IDs and their exceptions only.
+func parseLicenseExpression(s string) (*licenseExpression, error) {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s))
+	p := licenseParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q in %q", errInvalidLicenseExpression, p.tokens[p.pos], s)
+	}
+	return expr, nil
+}
+
+type licenseParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *licenseParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return strings.ToUpper(p.tokens[p.pos])
+	}
+	return ""
+}
+
+// parseOr parses "and-expression [OR and-expression]...", OR having a lower precedence than AND.
+func (p *licenseParser) parseOr() (*licenseExpression, error) {
+	return p.parseCompound("OR", p.parseAnd)
+}
+
+// parseAnd parses "simple-expression [AND simple-expression]...".
+func (p *licenseParser) parseAnd() (*licenseExpression, error) {
+	return p.parseCompound("AND", p.parseSimple)
+}
+
+func (p *licenseParser) parseCompound(
+	operator string, parseOperand func() (*licenseExpression, error),
+) (*licenseExpression, error) {
+	first, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*licenseExpression{first}
+	for p.peek() == operator {
+		p.pos++
+		operand, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &licenseExpression{operator: operator, operands: operands}, nil
+}
+
+// parseSimple parses "(expression)" or "license-id[+] [WITH exception-id]".
+func (p *licenseParser) parseSimple() (*licenseExpression, error) {
+	switch token := p.peek(); token {
+	case "":
+		return nil, fmt.Errorf("%w: unexpected end", errInvalidLicenseExpression)
+	case "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("%w: missing closing parenthesis", errInvalidLicenseExpression)
+		}
+		p.pos++
+		return expr, nil
+	case ")", "AND", "OR", "WITH":
+		return nil, fmt.Errorf("%w: unexpected %q", errInvalidLicenseExpression, token)
+	}
+	expr := &licenseExpression{id: strings.TrimSuffix(p.tokens[p.pos], "+")}
+	p.pos++
+	if p.peek() == "WITH" {
+		p.pos++
+		if token := p.peek(); token == "" || token == "(" || token == ")" {
+			return nil, fmt.Errorf("%w: missing license exception", errInvalidLicenseExpression)
+		}
+		expr.exception = p.tokens[p.pos]
+		p.pos++
+	}
+	return expr, nil
+}
@@ -0,0 +1,279 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLicensePolicy_Evaluate(t *testing.T) {
+	t.Parallel()
+	denyAGPL := &LicensePolicy{Deny: []string{"AGPL-3.0-only"}, WarnOnUnknown: true}
+	allowPermissive := &LicensePolicy{Allow: []string{"MIT", "Apache-2.0"}}
+	allowClasspath := &LicensePolicy{
+		Allow: []string{"GPL-2.0-only WITH Classpath-exception-2.0"},
+		Deny:  []string{"GPL-2.0-only", "Apache-2.0 WITH LLVM-exception"},
+	}
+	tests := []struct {
+		policy  *LicensePolicy
+		name    string
+		license string
+		want    Verdict
+	}{
+		{name: "allowed by default", policy: denyAGPL, license: "MIT", want: VerdictPass},
+		{name: "denied license", policy: denyAGPL, license: "AGPL-3.0-only", want: VerdictFail},
+		{name: "denied license ID is case-insensitive", policy: denyAGPL, license: "agpl-3.0-only", want: VerdictFail},
+		{name: "denied license with a choice", policy: denyAGPL, license: "AGPL-3.0-only OR MIT", want: VerdictPass},
+		{name: "denied license required", policy: denyAGPL, license: "(AGPL-3.0-only AND MIT)", want: VerdictFail},
+		{name: "unknown license warns", policy: denyAGPL, license: "", want: VerdictWarn},
+		{name: "unrecognized expression warns", policy: denyAGPL, license: "MIT AND (", want: VerdictWarn},
+		{name: "unknown license without warning", policy: allowPermissive, license: "", want: VerdictPass},
+		{name: "not in the allow list", policy: allowPermissive, license: "GPL-2.0-or-later", want: VerdictFail},
+		{
+			name:    "allowed with an exception and a choice",
+			policy:  allowPermissive,
+			license: "GPL-2.0-only WITH Classpath-exception-2.0 OR (Apache-2.0 AND MIT)",
+			want:    VerdictPass,
+		},
+		{
+			name:    "allowed license ID with any exception",
+			policy:  allowPermissive,
+			license: "Apache-2.0 WITH LLVM-exception",
+			want:    VerdictPass,
+		},
+		{
+			name:    "allowed exception of a denied license ID",
+			policy:  allowClasspath,
+			license: "GPL-2.0-only WITH classpath-exception-2.0",
+			want:    VerdictPass,
+		},
+		{name: "denied license ID without the exception", policy: allowClasspath, license: "GPL-2.0-only", want: VerdictFail},
+		{
+			name:    "denied license ID with another exception",
+			policy:  allowClasspath,
+			license: "GPL-2.0-only WITH GCC-exception-3.1",
+			want:    VerdictFail,
+		},
+		{
+			name:    "denied exception",
+			policy:  &LicensePolicy{Deny: []string{"Apache-2.0 WITH LLVM-exception"}},
+			license: "Apache-2.0 WITH LLVM-exception",
+			want:    VerdictFail,
+		},
+		{
+			name:    "license ID without the denied exception",
+			policy:  &LicensePolicy{Deny: []string{"Apache-2.0 WITH LLVM-exception"}},
+			license: "Apache-2.0",
+			want:    VerdictPass,
+		},
+		{
+			name:    "denied license as specific as an allowed one",
+			policy:  &LicensePolicy{Allow: []string{"MIT"}, Deny: []string{"mit"}},
+			license: "MIT",
+			want:    VerdictFail,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := VerdictPass
+			if v := tt.policy.evaluate(tt.license); v != nil {
+				got = v.Verdict
+			}
+			if got != tt.want {
+				t.Errorf("evaluate(%q) = %v, want %v", tt.license, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetLicenseChanges(t *testing.T) {
+	t.Parallel()
+	mit, apache, noAssertion := "MIT", "Apache-2.0", "NOASSERTION"
+	dependency := func(license *string) *DependencyCheckResult {
+		return &DependencyCheckResult{Name: "dep", License: license}
+	}
+	//nolint
+	tests := []struct {
+		name    string
+		changes []DependencyChange
+		want    [][2]string
+	}{
+		{
+			name:    "license added",
+			changes: []DependencyChange{{Old: dependency(nil), New: dependency(&mit), ChangeType: Updated}},
+			want:    [][2]string{{"", "MIT"}},
+		},
+		{
+			name:    "license removed",
+			changes: []DependencyChange{{Old: dependency(&mit), New: dependency(&noAssertion), ChangeType: Updated}},
+			want:    [][2]string{{"MIT", ""}},
+		},
+		{
+			name:    "license changed",
+			changes: []DependencyChange{{Old: dependency(&mit), New: dependency(&apache), ChangeType: Updated}},
+			want:    [][2]string{{"MIT", "Apache-2.0"}},
+		},
+		{
+			name:    "license unchanged",
+			changes: []DependencyChange{{Old: dependency(&mit), New: dependency(&mit), ChangeType: Updated}},
+			want:    [][2]string{},
+		},
+		{
+			name:    "unknown licenses are the same",
+			changes: []DependencyChange{{Old: dependency(nil), New: dependency(&noAssertion), ChangeType: Updated}},
+			want:    [][2]string{},
+		},
+		{
+			name: "added and removed dependencies are not changes",
+			changes: []DependencyChange{
+				{New: dependency(&mit), ChangeType: Added},
+				{Old: dependency(&apache), ChangeType: Removed},
+			},
+			want: [][2]string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := [][2]string{}
+			for _, c := range GetLicenseChanges(tt.changes) {
+				got = append(got, [2]string{c.Old, c.New})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetLicenseChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDependencydiffPolicy_Licenses(t *testing.T) {
+	t.Parallel()
+	//nolint
+	tests := []struct {
+		name    string
+		policy  string
+		want    *LicensePolicy
+		wantErr bool
+	}{
+		{
+			name:   "no license policy",
+			policy: "fail_on_severity: high\n",
+			want:   nil,
+		},
+		{
+			name:   "allow and deny lists",
+			policy: "licenses:\n  allow: [MIT, Apache-2.0]\n  deny:\n    - AGPL-3.0-only\n  warn_on_unknown: true\n",
+			want: &LicensePolicy{
+				Allow:         []string{"MIT", "Apache-2.0"},
+				Deny:          []string{"AGPL-3.0-only"},
+				WarnOnUnknown: true,
+			},
+		},
+		{
+			name:   "license with an exception",
+			policy: "licenses:\n  allow: [\"GPL-2.0-or-later WITH Classpath-exception-2.0\", GPL-3.0+]\n",
+			want: &LicensePolicy{
+				Allow: []string{"GPL-2.0-or-later WITH Classpath-exception-2.0", "GPL-3.0+"},
+			},
+		},
+		{
+			name:    "missing exception",
+			policy:  "licenses:\n  deny: [\"GPL-2.0-only WITH\"]\n",
+			wantErr: true,
+		},
+		{
+			name:    "expression instead of a license ID",
+			policy:  "licenses:\n  deny: [\"MIT OR Apache-2.0\"]\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty license ID",
+			policy:  "licenses:\n  allow: [\"\"]\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			policy, err := parseDependencydiffPolicy([]byte(tt.policy))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDependencydiffPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(policy.Licenses, tt.want) {
+				t.Errorf("Licenses = %+v, want %+v", policy.Licenses, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateDependencydiffPolicy_Licenses(t *testing.T) {
+	t.Parallel()
+	added, removed := Added, Removed
+	version := "1.0.0"
+	mit, gpl, agpl := "MIT", "GPL-3.0-only", "AGPL-3.0-only"
+	results := []DependencyCheckResult{
+		{Name: "permissive", ChangeType: &added, Version: &version, License: &mit},
+		{Name: "copyleft", ChangeType: &added, Version: &version, License: &gpl},
+		{Name: "unknown", ChangeType: &added, Version: &version},
+		// The license of a removed dependency is not evaluated.
+		{Name: "removed", ChangeType: &removed, Version: &version, License: &agpl},
+	}
+	//nolint
+	tests := []struct {
+		name        string
+		licenses    *LicensePolicy
+		wantVerdict Verdict
+		want        map[string]Verdict
+	}{
+		{
+			name:        "denied license fails",
+			licenses:    &LicensePolicy{Deny: []string{"GPL-3.0-only", "AGPL-3.0-only"}},
+			wantVerdict: VerdictFail,
+			want:        map[string]Verdict{"copyleft": VerdictFail},
+		},
+		{
+			name:        "license not allowed fails",
+			licenses:    &LicensePolicy{Allow: []string{"Apache-2.0"}},
+			wantVerdict: VerdictFail,
+			want:        map[string]Verdict{"permissive": VerdictFail, "copyleft": VerdictFail},
+		},
+		{
+			name:        "unknown license warns",
+			licenses:    &LicensePolicy{Allow: []string{"MIT", "GPL-3.0-only"}, WarnOnUnknown: true},
+			wantVerdict: VerdictWarn,
+			want:        map[string]Verdict{"unknown": VerdictWarn},
+		},
+		{
+			name:        "allowed licenses pass",
+			licenses:    &LicensePolicy{Allow: []string{"MIT", "GPL-3.0-only"}},
+			wantVerdict: VerdictPass,
+			want:        map[string]Verdict{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			e, err := EvaluateDependencydiffPolicy(results, &DependencydiffPolicy{Licenses: tt.licenses}, nil)
+			if err != nil {
+				t.Fatalf("EvaluateDependencydiffPolicy() error = %v", err)
+			}
+			if e.Verdict != tt.wantVerdict {
+				t.Errorf("Verdict = %v, want %v", e.Verdict, tt.wantVerdict)
+			}
+			got := map[string]Verdict{}
+			for _, v := range e.Violations {
+				if v.Rule != RuleLicense {
+					t.Errorf("got violation of rule %s, want %s", v.Rule, RuleLicense)
+				}
+				got[v.Dependency.Name] = v.Verdict
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got violations %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// dependencyLineMatchers match the line declaring a dependency in common manifests, by the base name of
+// the manifest. Other manifests are matched by the first line containing the name of the dependency.
+var dependencyLineMatchers = map[string]func(line, name string) bool{
+	"go.mod":            matchGoModLine,
+	"package.json":      matchJSONKeyLine,
+	"package-lock.json": matchJSONKeyLine,
+	"requirements.txt":  matchRequirementLine,
+	"pom.xml":           matchPomLine,
+	"Cargo.toml":        matchTOMLKeyLine,
+	"Gemfile":           matchGemfileLine,
+}
+
+// FindDependencyLine finds the line declaring the dependency in its manifest, which is read relative to the
+// current working directory, e.g. the checked out repository in a GitHub Action. It returns 0 if the manifest
+// or the dependency cannot be found.
+func FindDependencyLine(d *DependencyCheckResult) uint {
+	if d.ManifestPath == nil || d.Name == "" {
+		return 0
+	}
+	f, err := os.Open(*d.ManifestPath)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	match, found := dependencyLineMatchers[filepath.Base(*d.ManifestPath)]
+	if !found {
+		match = strings.Contains
+	}
+	scanner := bufio.NewScanner(f)
+	for line := uint(1); scanner.Scan(); line++ {
+		if match(scanner.Text(), d.Name) {
+			return line
+		}
+	}
+	return 0
+}
+
+// matchGoModLine matches a require directive, either single-line or in a block.
+func matchGoModLine(line, name string) bool {
+	fields := strings.Fields(line)
+	if len(fields) > 0 && fields[0] == "require" {
+		fields = fields[1:]
+	}
+	return len(fields) > 1 && fields[0] == name
+}
+
+// matchJSONKeyLine matches a key of a JSON object, such as a dependency of package.json or a package of
+// package-lock.json keyed by its node_modules path.
+func matchJSONKeyLine(line, name string) bool {
+	key, _, found := strings.Cut(strings.TrimSpace(line), ":")
+	if !found {
+		return false
+	}
+	key = strings.Trim(strings.TrimSpace(key), `"`)
+	return key == name || strings.HasSuffix(key, "node_modules/"+name)
+}
+
+var (
+	requirementNameEnd  = regexp.MustCompile(`[\s=<>!~;\[@]`)
+	pythonNameSeparator = regexp.MustCompile(`[-_.]+`)
+)
+
+// matchRequirementLine matches a requirement of a pip requirements file, comparing the normalized names.
+func matchRequirementLine(line, name string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+		return false
+	}
+	if loc := requirementNameEnd.FindStringIndex(line); loc != nil {
+		line = line[:loc[0]]
+	}
+	return normalizePythonName(line) == normalizePythonName(name)
+}
+
+// normalizePythonName normalizes a Python package name as in PEP 503.
+func normalizePythonName(name string) string {
+	return pythonNameSeparator.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// matchPomLine matches the artifactId of a Maven dependency, which is named as groupId:artifactId.
+func matchPomLine(line, name string) bool {
+	artifact := name
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		artifact = name[i+1:]
+	}
+	return strings.Contains(line, "<artifactId>"+artifact+"</artifactId>")
+}
+
+// matchTOMLKeyLine matches a dependency of Cargo.toml, declared either as a key or as a table.
+func matchTOMLKeyLine(line, name string) bool {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "[") {
+		return strings.HasSuffix(strings.Trim(line, "[]"), "dependencies."+name)
+	}
+	key, _, found := strings.Cut(line, "=")
+	return found && strings.Trim(strings.TrimSpace(key), `"`) == name
+}
+
+// matchGemfileLine matches a gem declaration.
+func matchGemfileLine(line, name string) bool {
+	fields := strings.Fields(strings.ReplaceAll(line, ",", " "))
+	return len(fields) > 1 && fields[0] == "gem" && strings.Trim(fields[1], `"'`) == name
+}
@@ -0,0 +1,104 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindDependencyLine(t *testing.T) {
+	t.Parallel()
+	//nolint
+	tests := []struct {
+		name       string
+		manifest   string
+		content    string
+		dependency string
+		want       uint
+	}{
+		{
+			name:       "go.mod block",
+			manifest:   "go.mod",
+			content:    "module example.com/m\n\nrequire (\n\tgithub.com/a/b-extra v1.0.0\n\tgithub.com/a/b v1.2.0\n)\n",
+			dependency: "github.com/a/b",
+			want:       5,
+		},
+		{
+			name:       "go.mod single line",
+			manifest:   "go.mod",
+			content:    "module example.com/m\n\nrequire github.com/a/b v1.2.0\n",
+			dependency: "github.com/a/b",
+			want:       3,
+		},
+		{
+			name:       "package.json",
+			manifest:   "package.json",
+			content:    "{\n  \"name\": \"lodash-app\",\n  \"dependencies\": {\n    \"lodash\": \"^4.17.21\"\n  }\n}\n",
+			dependency: "lodash",
+			want:       4,
+		},
+		{
+			name:       "package-lock.json",
+			manifest:   "package-lock.json",
+			content:    "{\n  \"packages\": {\n    \"node_modules/@scope/pkg\": {\n      \"version\": \"1.0.0\"\n    }\n  }\n}\n",
+			dependency: "@scope/pkg",
+			want:       3,
+		},
+		{
+			name:       "requirements.txt",
+			manifest:   "requirements.txt",
+			content:    "# requests-oauthlib is pinned\nrequests-oauthlib==1.3.1\nRequests[socks]>=2.28\n",
+			dependency: "requests",
+			want:       3,
+		},
+		{
+			name:       "pom.xml",
+			manifest:   "pom.xml",
+			content:    "<dependency>\n  <groupId>org.example</groupId>\n  <artifactId>lib</artifactId>\n</dependency>\n",
+			dependency: "org.example:lib",
+			want:       3,
+		},
+		{
+			name:       "Cargo.toml",
+			manifest:   "Cargo.toml",
+			content:    "[package]\nname = \"serde-app\"\n\n[dependencies]\nserde = { version = \"1.0\" }\n",
+			dependency: "serde",
+			want:       5,
+		},
+		{
+			name:       "Gemfile",
+			manifest:   "Gemfile",
+			content:    "source 'https://rubygems.org'\ngem 'rails-html', '~> 1.0'\ngem 'rails', '~> 7.0'\n",
+			dependency: "rails",
+			want:       3,
+		},
+		{
+			name:       "other manifest",
+			manifest:   "build.gradle",
+			content:    "dependencies {\n  implementation 'org.example:lib:1.0'\n}\n",
+			dependency: "org.example:lib",
+			want:       2,
+		},
+		{
+			name:       "not found",
+			manifest:   "go.mod",
+			content:    "module example.com/m\n",
+			dependency: "github.com/a/b",
+			want:       0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), tt.manifest)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("os.WriteFile: %v", err)
+			}
+			got := FindDependencyLine(&DependencyCheckResult{Name: tt.dependency, ManifestPath: &path})
+			if got != tt.want {
+				t.Errorf("got line %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
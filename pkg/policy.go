@@ -0,0 +1,309 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ossf/scorecard/v4/checker"
+	"github.com/ossf/scorecard/v4/checks"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sce "github.com/ossf/scorecard/v4/errors"
+	scpkg "github.com/ossf/scorecard/v4/pkg"
+)
+
+var (
+	errInvalidCheck    = errors.New("invalid check name")
+	errInvalidWeight   = errors.New("invalid check weight")
+	errInvalidSeverity = errors.New("invalid severity level")
+	errInvalidScore    = errors.New("invalid score")
+)
+
+// riskWeights are the default check weights used by Scorecard, keyed by the risk level of a check.
+var riskWeights = map[string]float64{"Critical": 10, "High": 7.5, "Medium": 5, "Low": 2.5}
+
+// DependencydiffPolicy is the user-supplied policy of a dependency-diff run.
+type DependencydiffPolicy struct {
+	// Weights overrides the risk-based weights of checks when computing the aggregate score.
+	// Checks not found in the map keep the default Scorecard weight, and a zero weight excludes the check.
+	Weights map[string]float64 `yaml:"weights"`
+
+	// Exclude is a list of check names that are left out of the aggregate score.
+	Exclude []string `yaml:"exclude"`
+
+	// FailOnSeverity fails the run when a dependency change introduces a vulnerability at or above
+	// this severity level, which is one of LOW, MEDIUM (or MODERATE), HIGH and CRITICAL.
+	// Vulnerabilities already present at BASE only give a warning.
+	FailOnSeverity SeverityLevel `yaml:"fail_on_severity"`
+
+	// FailOnUnknownSeverity tells whether vulnerabilities of unknown severity are at FailOnSeverity, which is
+	// the default. If false, they are ignored by FailOnSeverity.
+	FailOnUnknownSeverity *bool `yaml:"fail_on_unknown_severity"`
+
+	// Licenses is the policy on the declared licenses of the added and updated dependencies.
+	Licenses *LicensePolicy `yaml:"licenses"`
+
+	// MinScore gives a warning for added and updated dependencies with an aggregate score below it.
+	MinScore *float64 `yaml:"min_score"`
+}
+
+// Verdict is the outcome of evaluating a policy.
+type Verdict string
+
+const (
+	// VerdictPass suggests no policy rule is violated.
+	VerdictPass Verdict = "pass"
+	// VerdictWarn suggests some policy rules are violated, but none of them fails the run.
+	VerdictWarn Verdict = "warn"
+	// VerdictFail suggests some policy rules are violated and the run fails.
+	VerdictFail Verdict = "fail"
+)
+
+// Policy rules.
+const (
+	// RuleVulnerability is the policy rule on vulnerabilities of dependencies.
+	RuleVulnerability = "vulnerability"
+	// RuleLicense is the policy rule on licenses of dependencies.
+	RuleLicense = "license"
+	// RuleScore is the policy rule on aggregate scores of dependencies.
+	RuleScore = "score"
+)
+
+// PolicyViolation is a policy rule violated by a dependency.
+type PolicyViolation struct {
+	// Dependency is the dependency violating the rule.
+	Dependency *DependencyCheckResult
+
+	// Rule is the name of the violated rule.
+	Rule string
+
+	// Message describes the violation.
+	Message string
+
+	// Verdict is either VerdictWarn or VerdictFail.
+	Verdict Verdict
+}
+
+// PolicyEvaluation is the result of evaluating a policy on dependency-diff results.
+type PolicyEvaluation struct {
+	// Verdict is the overall verdict, which is the most severe verdict of the violations.
+	Verdict Verdict
+
+	// Violations are the violated policy rules.
+	Violations []PolicyViolation
+
+	// Vulnerabilities are the vulnerabilities introduced, fixed and left unchanged by the dependency changes.
+	Vulnerabilities VulnerabilityChanges
+
+	// LicenseChanges are the changes of the declared licenses of the updated dependencies.
+	LicenseChanges []LicenseChange
+}
+
+func (e *PolicyEvaluation) addViolation(v PolicyViolation) {
+	e.Violations = append(e.Violations, v)
+	if v.Verdict == VerdictFail || e.Verdict == VerdictPass {
+		e.Verdict = v.Verdict
+	}
+}
+
+// ReadDependencydiffPolicy takes a policy file and returns a DependencydiffPolicy.
+// A nil policy is returned if the file path is empty, in which case Scorecard's defaults apply.
+func ReadDependencydiffPolicy(policyFile string) (*DependencydiffPolicy, error) {
+	if policyFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(policyFile)
+	if err != nil {
+		return nil, sce.WithMessage(sce.ErrScorecardInternal,
+			fmt.Sprintf("os.ReadFile: %v", err))
+	}
+	policy, err := parseDependencydiffPolicy(data)
+	if err != nil {
+		return nil, sce.WithMessage(sce.ErrScorecardInternal,
+			fmt.Sprintf("parseDependencydiffPolicy: %v", err))
+	}
+	return policy, nil
+}
+
+func parseDependencydiffPolicy(b []byte) (*DependencydiffPolicy, error) {
+	policy := DependencydiffPolicy{}
+	if err := yaml.Unmarshal(b, &policy); err != nil {
+		return nil, fmt.Errorf("yaml.Unmarshal: %w", err)
+	}
+	allChecks := checks.GetAll()
+	for name, weight := range policy.Weights {
+		if _, exists := allChecks[name]; !exists {
+			return nil, fmt.Errorf("%w: %s", errInvalidCheck, name)
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("%w: %v for %s", errInvalidWeight, weight, name)
+		}
+	}
+	for _, name := range policy.Exclude {
+		if _, exists := allChecks[name]; !exists {
+			return nil, fmt.Errorf("%w: %s", errInvalidCheck, name)
+		}
+	}
+	if policy.MinScore != nil && (*policy.MinScore < checker.MinResultScore || *policy.MinScore > checker.MaxResultScore) {
+		return nil, fmt.Errorf("%w: %v", errInvalidScore, *policy.MinScore)
+	}
+	if policy.Licenses != nil {
+		if err := policy.Licenses.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if policy.FailOnSeverity != "" {
+		policy.FailOnSeverity = SeverityLevel(strings.ToUpper(string(policy.FailOnSeverity)))
+		if !policy.FailOnSeverity.IsThreshold() {
+			return nil, fmt.Errorf("%w: %s", errInvalidSeverity, policy.FailOnSeverity)
+		}
+	}
+	return &policy, nil
+}
+
+// failsOn determines if a vulnerability of the severity level is at the FailOnSeverity threshold.
+func (p *DependencydiffPolicy) failsOn(severity SeverityLevel) bool {
+	if severity.IsUnknown() && p.FailOnUnknownSeverity != nil && !*p.FailOnUnknownSeverity {
+		return false
+	}
+	return severity.AtLeast(p.FailOnSeverity)
+}
+
+func (p *DependencydiffPolicy) isExcluded(checkName string) bool {
+	for _, name := range p.Exclude {
+		if name == checkName {
+			return true
+		}
+	}
+	return false
+}
+
+// AggregateScoreOf gets the aggregate score of a dependency using the weights of the policy, which is
+// inconclusive if the checks were not run or the score cannot be computed, so that other dependencies can
+// still be reported.
+func AggregateScoreOf(d *DependencyCheckResult, checkDocs docs.Doc, policy *DependencydiffPolicy) float64 {
+	scResult := d.ScorecardResultWithError.ScorecardResult
+	if scResult == nil {
+		return checker.InconclusiveResultScore
+	}
+	score, err := policy.GetAggregateScore(scResult, checkDocs)
+	if err != nil {
+		return checker.InconclusiveResultScore
+	}
+	return score
+}
+
+// GetAggregateScore gets the aggregate score of a Scorecard result using the weights of the policy.
+// A nil policy, or one without weights and exclusions, gives the same score as ScorecardResult.GetAggregateScore.
+func (p *DependencydiffPolicy) GetAggregateScore(r *scpkg.ScorecardResult, checkDocs docs.Doc) (float64, error) {
+	if p == nil || (len(p.Weights) == 0 && len(p.Exclude) == 0) {
+		return r.GetAggregateScore(checkDocs)
+	}
+	total := float64(0)
+	score := float64(0)
+	for i := range r.Checks {
+		check := r.Checks[i]
+		if p.isExcluded(check.Name) {
+			continue
+		}
+		weight, found := p.Weights[check.Name]
+		if !found {
+			doc, err := checkDocs.GetCheck(check.Name)
+			if err != nil {
+				return checker.InconclusiveResultScore,
+					sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("GetCheck: %s: %v", check.Name, err))
+			}
+			weight, found = riskWeights[doc.GetRisk()]
+			if !found {
+				return checker.InconclusiveResultScore,
+					sce.WithMessage(sce.ErrScorecardInternal,
+						fmt.Sprintf("Invalid risk for %s: '%s'", check.Name, doc.GetRisk()))
+			}
+		}
+		// This indicates an inconclusive score or an excluded check.
+		if check.Score < checker.MinResultScore || weight == 0 {
+			continue
+		}
+		total += weight
+		score += weight * float64(check.Score)
+	}
+	// Inconclusive result.
+	if total == 0 {
+		return checker.InconclusiveResultScore, nil
+	}
+	return score / total, nil
+}
+
+// EvaluateDependencydiffPolicy evaluates the policy on the dependency-diff results. A nil policy has no rules,
+// in which case the verdict is VerdictPass, but vulnerability and license changes are still reported.
+func EvaluateDependencydiffPolicy(
+	results []DependencyCheckResult, policy *DependencydiffPolicy, checkDocs docs.Doc,
+) (*PolicyEvaluation, error) {
+	changes := PairDependencyChanges(results)
+	evaluation := &PolicyEvaluation{
+		Verdict:         VerdictPass,
+		Vulnerabilities: GetVulnerabilityChanges(changes),
+		LicenseChanges:  GetLicenseChanges(changes),
+	}
+	if policy == nil {
+		return evaluation, nil
+	}
+	if policy.FailOnSeverity != "" {
+		for _, dv := range evaluation.Vulnerabilities.Introduced {
+			if policy.failsOn(dv.Vulnerability.Severity) {
+				evaluation.addViolation(PolicyViolation{
+					Dependency: dv.Dependency,
+					Rule:       RuleVulnerability,
+					Message: fmt.Sprintf("introduces %s vulnerability %s: %s",
+						dv.Vulnerability.Severity, dv.Vulnerability.ID, dv.Vulnerability.Title),
+					Verdict: VerdictFail,
+				})
+			}
+		}
+		for _, dv := range evaluation.Vulnerabilities.Unchanged {
+			if policy.failsOn(dv.Vulnerability.Severity) {
+				evaluation.addViolation(PolicyViolation{
+					Dependency: dv.Dependency,
+					Rule:       RuleVulnerability,
+					Message: fmt.Sprintf("keeps pre-existing %s vulnerability %s: %s",
+						dv.Vulnerability.Severity, dv.Vulnerability.ID, dv.Vulnerability.Title),
+					Verdict: VerdictWarn,
+				})
+			}
+		}
+	}
+	if policy.Licenses != nil {
+		for _, c := range changes {
+			if c.New == nil {
+				continue
+			}
+			if violation := policy.Licenses.evaluate(knownLicense(c.New.License)); violation != nil {
+				violation.Dependency = c.New
+				evaluation.addViolation(*violation)
+			}
+		}
+	}
+	if policy.MinScore != nil {
+		for _, c := range changes {
+			if c.New == nil || c.New.ScorecardResultWithError.ScorecardResult == nil {
+				continue
+			}
+			score, err := policy.GetAggregateScore(c.New.ScorecardResultWithError.ScorecardResult, checkDocs)
+			if err != nil {
+				return nil, err
+			}
+			if score != checker.InconclusiveResultScore && score < *policy.MinScore {
+				evaluation.addViolation(PolicyViolation{
+					Dependency: c.New,
+					Rule:       RuleScore,
+					Message:    fmt.Sprintf("has an aggregate score of %.1f, below the minimum of %.1f", score, *policy.MinScore),
+					Verdict:    VerdictWarn,
+				})
+			}
+		}
+	}
+	return evaluation, nil
+}
@@ -0,0 +1,287 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/ossf/scorecard/v4/checker"
+	"github.com/ossf/scorecard/v4/checks"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	scpkg "github.com/ossf/scorecard/v4/pkg"
+)
+
+func TestParseDependencydiffPolicy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		policy  string
+		wantErr bool
+	}{
+		{
+			name:    "empty policy",
+			policy:  "",
+			wantErr: false,
+		},
+		{
+			name:    "valid weights and exclusions",
+			policy:  "weights:\n  Code-Review: 10\n  License: 0\nexclude:\n  - Fuzzing\n",
+			wantErr: false,
+		},
+		{
+			name:    "unknown check in weights",
+			policy:  "weights:\n  Not-A-Check: 1\n",
+			wantErr: true,
+		},
+		{
+			name:    "negative weight",
+			policy:  "weights:\n  Code-Review: -1\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown check in exclusions",
+			policy:  "exclude:\n  - Not-A-Check\n",
+			wantErr: true,
+		},
+		{
+			name:    "severity threshold",
+			policy:  "fail_on_severity: moderate\n",
+			wantErr: false,
+		},
+		{
+			name:    "NONE severity threshold",
+			policy:  "fail_on_severity: NONE\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown severity threshold",
+			policy:  "fail_on_severity: unknown\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid severity threshold",
+			policy:  "fail_on_severity: urgent\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := parseDependencydiffPolicy([]byte(tt.policy)); (err != nil) != tt.wantErr {
+				t.Errorf("parseDependencydiffPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDependencydiffPolicy_GetAggregateScore(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	result := &scpkg.ScorecardResult{
+		Checks: []checker.CheckResult{
+			{Name: checks.CheckCodeReview, Score: 10},
+			{Name: checks.CheckLicense, Score: 0},
+			{Name: checks.CheckFuzzing, Score: checker.InconclusiveResultScore},
+		},
+	}
+	stock, err := result.GetAggregateScore(checkDocs)
+	if err != nil {
+		t.Fatalf("GetAggregateScore: %v", err)
+	}
+	tests := []struct {
+		policy *DependencydiffPolicy
+		name   string
+		want   float64
+	}{
+		{
+			name:   "nil policy uses Scorecard weights",
+			policy: nil,
+			want:   stock,
+		},
+		{
+			name:   "excluded check is ignored",
+			policy: &DependencydiffPolicy{Exclude: []string{checks.CheckLicense}},
+			want:   10,
+		},
+		{
+			name:   "zero weight excludes the check",
+			policy: &DependencydiffPolicy{Weights: map[string]float64{checks.CheckCodeReview: 0}},
+			want:   0,
+		},
+		{
+			name: "custom weights",
+			policy: &DependencydiffPolicy{
+				Weights: map[string]float64{checks.CheckCodeReview: 3, checks.CheckLicense: 1},
+			},
+			want: 7.5,
+		},
+		{
+			name:   "all checks excluded is inconclusive",
+			policy: &DependencydiffPolicy{Exclude: []string{checks.CheckCodeReview, checks.CheckLicense}},
+			want:   checker.InconclusiveResultScore,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.policy.GetAggregateScore(result, checkDocs)
+			if err != nil {
+				t.Fatalf("GetAggregateScore() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetAggregateScore() = %v, want %v", got, tt.want)
+			}
+			d := &DependencyCheckResult{ScorecardResultWithError: ScorecardResultWithError{ScorecardResult: result}}
+			if got := AggregateScoreOf(d, checkDocs, tt.policy); got != tt.want {
+				t.Errorf("AggregateScoreOf() = %v, want %v", got, tt.want)
+			}
+			if got := AggregateScoreOf(&DependencyCheckResult{}, checkDocs, tt.policy); got != checker.InconclusiveResultScore {
+				t.Errorf("AggregateScoreOf() = %v without checks, want inconclusive", got)
+			}
+		})
+	}
+}
+
+func TestEvaluateDependencydiffPolicy(t *testing.T) {
+	t.Parallel()
+	added, removed := Added, Removed
+	oldVersion, newVersion := "1.0.0", "1.1.0"
+	low := Vulnerability{ID: "GHSA-low", Severity: Low}
+	high := Vulnerability{ID: "GHSA-high", Severity: High}
+	critical := Vulnerability{ID: "GHSA-critical", Severity: Critical}
+	results := []DependencyCheckResult{
+		// An updated dependency fixing GHSA-low and keeping GHSA-high.
+		{Name: "updated", ChangeType: &removed, Version: &oldVersion, Vulnerabilities: []Vulnerability{low, high}},
+		{Name: "updated", ChangeType: &added, Version: &newVersion, Vulnerabilities: []Vulnerability{high}},
+		// An added dependency introducing GHSA-critical.
+		{Name: "added", ChangeType: &added, Version: &newVersion, Vulnerabilities: []Vulnerability{critical}},
+		// A removed dependency fixing GHSA-low.
+		{Name: "removed", ChangeType: &removed, Version: &oldVersion, Vulnerabilities: []Vulnerability{low}},
+	}
+	tests := []struct {
+		policy         *DependencydiffPolicy
+		name           string
+		wantVerdict    Verdict
+		wantViolations int
+	}{
+		{
+			name:           "nil policy",
+			policy:         nil,
+			wantVerdict:    VerdictPass,
+			wantViolations: 0,
+		},
+		{
+			name:           "introduced vulnerability at the threshold fails",
+			policy:         &DependencydiffPolicy{FailOnSeverity: Critical},
+			wantVerdict:    VerdictFail,
+			wantViolations: 1,
+		},
+		{
+			name:           "pre-existing vulnerabilities at the threshold are warnings",
+			policy:         &DependencydiffPolicy{FailOnSeverity: High},
+			wantVerdict:    VerdictFail,
+			wantViolations: 2,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			e, err := EvaluateDependencydiffPolicy(results, tt.policy, nil)
+			if err != nil {
+				t.Fatalf("EvaluateDependencydiffPolicy() error = %v", err)
+			}
+			if e.Verdict != tt.wantVerdict {
+				t.Errorf("Verdict = %v, want %v", e.Verdict, tt.wantVerdict)
+			}
+			if len(e.Violations) != tt.wantViolations {
+				t.Errorf("got %d violations, want %d", len(e.Violations), tt.wantViolations)
+			}
+			vc := e.Vulnerabilities
+			if len(vc.Introduced) != 1 || len(vc.Fixed) != 2 || len(vc.Unchanged) != 1 {
+				t.Errorf("got %d introduced, %d fixed, %d unchanged vulnerabilities, want 1, 2, 1",
+					len(vc.Introduced), len(vc.Fixed), len(vc.Unchanged))
+			}
+		})
+	}
+}
+
+func TestEvaluateDependencydiffPolicy_UnknownSeverity(t *testing.T) {
+	t.Parallel()
+	added := Added
+	version := "1.0.0"
+	results := []DependencyCheckResult{
+		{
+			Name: "unclassified", ChangeType: &added, Version: &version,
+			Vulnerabilities: []Vulnerability{
+				{ID: "GHSA-unknown", Severity: Unknown},
+				{ID: "GHSA-empty"},
+				{ID: "GHSA-low", Severity: Low},
+			},
+		},
+	}
+	failOnUnknown := false
+	//nolint
+	tests := []struct {
+		name           string
+		policy         *DependencydiffPolicy
+		wantVerdict    Verdict
+		wantViolations int
+	}{
+		{
+			name:           "unknown severities are at the threshold by default",
+			policy:         &DependencydiffPolicy{FailOnSeverity: Critical},
+			wantVerdict:    VerdictFail,
+			wantViolations: 2,
+		},
+		{
+			name:           "unknown severities can be ignored",
+			policy:         &DependencydiffPolicy{FailOnSeverity: Critical, FailOnUnknownSeverity: &failOnUnknown},
+			wantVerdict:    VerdictPass,
+			wantViolations: 0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			e, err := EvaluateDependencydiffPolicy(results, tt.policy, nil)
+			if err != nil {
+				t.Fatalf("EvaluateDependencydiffPolicy() error = %v", err)
+			}
+			if e.Verdict != tt.wantVerdict || len(e.Violations) != tt.wantViolations {
+				t.Errorf("got verdict %v with %d violations, want %v with %d",
+					e.Verdict, len(e.Violations), tt.wantVerdict, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestSeverityLevel_AtLeast(t *testing.T) {
+	t.Parallel()
+	//nolint
+	tests := []struct {
+		severity  SeverityLevel
+		threshold SeverityLevel
+		want      bool
+	}{
+		{severity: Critical, threshold: High, want: true},
+		{severity: Moderate, threshold: Medium, want: true},
+		{severity: Low, threshold: Medium, want: false},
+		{severity: None, threshold: Low, want: false},
+		{severity: Unknown, threshold: Critical, want: true},
+		{severity: "", threshold: Critical, want: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.severity)+" "+string(tt.threshold), func(t *testing.T) {
+			t.Parallel()
+			if got := tt.severity.AtLeast(tt.threshold); got != tt.want {
+				t.Errorf("AtLeast() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ossf/scorecard/v4/checker"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sce "github.com/ossf/scorecard/v4/errors"
+)
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifRegion struct {
+	StartLine *uint `json:"startLine,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	Region           sarifRegion           `json:"region"`
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifDefaultConfig struct {
+	// "none", "note", "warning", "error",
+	// https://github.com/oasis-tcs/sarif-spec/blob/master/Schemata/sarif-schema-2.1.0.json#L1566.
+	Level string `json:"level"`
+}
+
+type sarifProperties struct {
+	Precision       string   `json:"precision"`
+	ProblemSeverity string   `json:"problem.severity"`
+	SeverityLevel   string   `json:"security-severity"`
+	Tags            []string `json:"tags"`
+}
+
+type sarifHelp struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type sarifRule struct {
+	ID            string             `json:"id"`
+	Name          string             `json:"name"`
+	HelpURI       string             `json:"helpUri,omitempty"`
+	ShortDesc     sarifText          `json:"shortDescription"`
+	FullDesc      sarifText          `json:"fullDescription"`
+	Help          sarifHelp          `json:"help"`
+	DefaultConfig sarifDefaultConfig `json:"defaultConfiguration"`
+	Properties    sarifProperties    `json:"properties"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	SemVersion     string      `json:"semanticVersion,omitempty"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifResult struct {
+	RuleID     string                `json:"ruleId"`
+	Level      string                `json:"level,omitempty"`
+	RuleIndex  int                   `json:"ruleIndex"`
+	Message    sarifText             `json:"message"`
+	Locations  []sarifLocation       `json:"locations,omitempty"`
+	Properties sarifResultProperties `json:"properties"`
+}
+
+// sarifResultProperties are the properties of a result, which tell whether the checks ran on the dependency.
+type sarifResultProperties struct {
+	Status DependencyStatus `json:"status"`
+}
+
+// sarifDependencyStatus tells whether the checks ran on a dependency, would run in a dry run, or why they were
+// skipped, so that the dependencies without results are listed as well.
+type sarifDependencyStatus struct {
+	Name       string           `json:"name"`
+	Version    string           `json:"version,omitempty"`
+	ChangeType ChangeType       `json:"changeType,omitempty"`
+	Status     DependencyStatus `json:"status"`
+}
+
+type sarifRunProperties struct {
+	Dependencies []sarifDependencyStatus `json:"dependencies"`
+}
+
+type sarifAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+type sarifRun struct {
+	AutomationDetails sarifAutomationDetails `json:"automationDetails"`
+	Tool              sarifTool              `json:"tool"`
+	// This MUST never be omitted or set as `nil`.
+	Results    []sarifResult      `json:"results"`
+	Properties sarifRunProperties `json:"properties"`
+}
+
+type sarif210 struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// policyRuleDocs documents the policy rules not derived from Scorecard checks.
+var policyRuleDocs = map[string]struct {
+	name, short, risk string
+	remediation       []string
+}{
+	RuleVulnerability: {
+		name:  "Dependency-Vulnerability",
+		short: "Determines if a dependency change introduces a known vulnerability.",
+		risk:  "High",
+		remediation: []string{
+			"Upgrade the dependency to a version fixing the vulnerability.",
+			"Remove the dependency if no fixed version is available.",
+		},
+	},
+	RuleLicense: {
+		name:  "Dependency-License",
+		short: "Determines if a dependency is declared with a license allowed by the policy.",
+		risk:  "Medium",
+		remediation: []string{
+			"Replace the dependency with one under an allowed license.",
+			"Ask for an exception to the license policy.",
+		},
+	},
+}
+
+// sarifRules holds the rules of a run, indexed by their IDs.
+type sarifRules struct {
+	run     *sarifRun
+	indexes map[string]int
+}
+
+func (r *sarifRules) add(rule sarifRule) int {
+	if index, exists := r.indexes[rule.ID]; exists {
+		return index
+	}
+	r.run.Tool.Driver.Rules = append(r.run.Tool.Driver.Rules, rule)
+	r.indexes[rule.ID] = len(r.run.Tool.Driver.Rules) - 1
+	return r.indexes[rule.ID]
+}
+
+func (r *sarifRules) addCheckRule(checkName, commit string, checkDocs docs.Doc) (int, string, error) {
+	doc, err := checkDocs.GetCheck(checkName)
+	if err != nil {
+		return 0, "", sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("GetCheck: %v: %s", err, checkName))
+	}
+	ruleID := createRuleID(checkName)
+	index := r.add(createSARIFRule(
+		checkName, ruleID, doc.GetDocumentationURL(commit), doc.GetShort(), doc.GetRisk(),
+		doc.GetRemediation(), doc.GetTags(),
+	))
+	return index, ruleID, nil
+}
+
+func (r *sarifRules) addPolicyRule(ruleName string) (int, string) {
+	doc := policyRuleDocs[ruleName]
+	ruleID := createRuleID(doc.name)
+	index := r.add(createSARIFRule(doc.name, ruleID, "", doc.short, doc.risk, doc.remediation, []string{"supply-chain"}))
+	return index, ruleID
+}
+
+// createRuleID creates a rule ID in Pascal case from a rule name, the same way as Scorecard.
+func createRuleID(name string) string {
+	return fmt.Sprintf("%sID", strings.ReplaceAll(name, "-", ""))
+}
+
+func createSARIFRule(name, ruleID, helpURI, shortDesc, risk string, remediation, tags []string) sarifRule {
+	markdown := fmt.Sprintf("**Severity**: %s\n\n**Remediation**:\n\n", risk)
+	for _, r := range remediation {
+		markdown += fmt.Sprintf("- %s\n", r)
+	}
+	return sarifRule{
+		ID:        ruleID,
+		Name:      name,
+		HelpURI:   helpURI,
+		ShortDesc: sarifText{Text: name},
+		FullDesc:  sarifText{Text: shortDesc},
+		Help: sarifHelp{
+			Text:     shortDesc,
+			Markdown: markdown,
+		},
+		DefaultConfig: sarifDefaultConfig{Level: "error"},
+		Properties: sarifProperties{
+			Tags:            tags,
+			Precision:       "high",
+			ProblemSeverity: riskToProblemSeverity(risk),
+			SeverityLevel:   riskToSeverityLevel(risk),
+		},
+	}
+}
+
+func riskToSeverityLevel(risk string) string {
+	// nolint:lll
+	// https://docs.github.com/en/code-security/code-scanning/integrating-with-code-scanning/sarif-support-for-code-scanning#reportingdescriptor-object.
+	// "over 9.0 is critical, 7.0 to 8.9 is high, 4.0 to 6.9 is medium and 3.9 or less is low".
+	switch risk {
+	case "Critical":
+		return "9.0"
+	case "High":
+		return "7.0"
+	case "Medium":
+		return "4.0"
+	default:
+		return "1.0"
+	}
+}
+
+func riskToProblemSeverity(risk string) string {
+	switch risk {
+	case "Critical", "High":
+		return "error"
+	case "Medium":
+		return "warning"
+	default:
+		return "recommendation"
+	}
+}
+
+func verdictToLevel(v Verdict) string {
+	if v == VerdictFail {
+		return "error"
+	}
+	return "warning"
+}
+
+// dependencyLocation locates a dependency at the line declaring it in its manifest, if found.
+func dependencyLocation(d *DependencyCheckResult) sarifLocation {
+	// GitHub needs a location to show the results, so a default one is used when the manifest is unknown.
+	uri := "no file associated with this alert"
+	if d.ManifestPath != nil && *d.ManifestPath != "" {
+		uri = *d.ManifestPath
+	}
+	line := FindDependencyLine(d)
+	if line == 0 {
+		line = checker.OffsetDefault
+	}
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: uri, URIBaseID: "%SRCROOT%"},
+			Region:           sarifRegion{StartLine: &line},
+		},
+	}
+}
+
+// DependencydiffResultsAsSARIF exports dependencydiff results in SARIF 2.1.0 format. Each policy violation is a
+// result located in the manifest of the dependency, and a dependency with a low aggregate score gives a result
+// for each of its checks scoring below the minimum score of the policy, with rules derived from the check docs.
+// The status of every dependency is listed in the properties of the run, and that of the dependency of a result
+// in the properties of the result.
+func DependencydiffResultsAsSARIF(depdiffResults []DependencyCheckResult,
+	checkDocs docs.Doc, policy *DependencydiffPolicy, writer io.Writer,
+) error {
+	evaluation, err := EvaluateDependencydiffPolicy(depdiffResults, policy, checkDocs)
+	if err != nil {
+		return err
+	}
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "Scorecard Dependency-diff",
+				InformationURI: "https://github.com/ossf/scorecard",
+			},
+		},
+		Results:           []sarifResult{},
+		AutomationDetails: sarifAutomationDetails{ID: "supply-chain/dependency-diff"},
+		Properties:        sarifRunProperties{Dependencies: []sarifDependencyStatus{}},
+	}
+	for i := range depdiffResults {
+		d := &depdiffResults[i]
+		status := sarifDependencyStatus{Name: d.Name, Version: derefString(d.Version), Status: d.Status}
+		if d.ChangeType != nil {
+			status.ChangeType = *d.ChangeType
+		}
+		run.Properties.Dependencies = append(run.Properties.Dependencies, status)
+	}
+	rules := sarifRules{run: &run, indexes: map[string]int{}}
+	// Add the rules of all the checks run to indicate which checks were run, as Scorecard does.
+	for i := range depdiffResults {
+		scResult := depdiffResults[i].ScorecardResultWithError.ScorecardResult
+		if scResult == nil {
+			continue
+		}
+		run.Tool.Driver.SemVersion = scResult.Scorecard.Version
+		for _, c := range scResult.Checks {
+			if _, _, err := rules.addCheckRule(c.Name, scResult.Scorecard.CommitSHA, checkDocs); err != nil {
+				return err
+			}
+		}
+	}
+	for _, v := range evaluation.Violations {
+		d := v.Dependency
+		name := fmt.Sprintf("%s @ %s", d.Name, derefString(d.Version))
+		if v.Rule != RuleScore {
+			index, ruleID := rules.addPolicyRule(v.Rule)
+			run.Results = append(run.Results, sarifResult{
+				RuleID:     ruleID,
+				Level:      verdictToLevel(v.Verdict),
+				RuleIndex:  index,
+				Message:    sarifText{Text: fmt.Sprintf("%s %s", name, v.Message)},
+				Locations:  []sarifLocation{dependencyLocation(d)},
+				Properties: sarifResultProperties{Status: d.Status},
+			})
+			continue
+		}
+		scResult := d.ScorecardResultWithError.ScorecardResult
+		for _, c := range scResult.Checks {
+			if c.Score == checker.InconclusiveResultScore || float64(c.Score) >= *policy.MinScore ||
+				policy.isExcluded(c.Name) {
+				continue
+			}
+			index, ruleID, err := rules.addCheckRule(c.Name, scResult.Scorecard.CommitSHA, checkDocs)
+			if err != nil {
+				return err
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    ruleID,
+				Level:     verdictToLevel(v.Verdict),
+				RuleIndex: index,
+				Message: sarifText{
+					Text: fmt.Sprintf("%s %s\n%s score is %d: %s", name, v.Message, c.Name, c.Score, c.Reason),
+				},
+				Locations:  []sarifLocation{dependencyLocation(d)},
+				Properties: sarifResultProperties{Status: d.Status},
+			})
+		}
+	}
+	sarif := sarif210{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "   ")
+	if err := encoder.Encode(sarif); err != nil {
+		return sce.WithMessage(sce.ErrScorecardInternal, err.Error())
+	}
+	return nil
+}
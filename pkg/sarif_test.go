@@ -0,0 +1,109 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ossf/scorecard/v4/checker"
+	"github.com/ossf/scorecard/v4/checks"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	scpkg "github.com/ossf/scorecard/v4/pkg"
+)
+
+func TestDependencydiffResultsAsSARIF(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	added := Added
+	version, manifest := "1.0.0", "go.mod"
+	minScore := float64(5)
+	policy := &DependencydiffPolicy{FailOnSeverity: High, MinScore: &minScore}
+	results := []DependencyCheckResult{
+		{
+			Name: "vulnerable", ChangeType: &added, Version: &version, ManifestPath: &manifest,
+			Vulnerabilities: []Vulnerability{{ID: "GHSA-high", Severity: High}},
+		},
+		{
+			Name: "low-score", ChangeType: &added, Version: &version, ManifestPath: &manifest,
+			ScorecardResultWithError: ScorecardResultWithError{
+				ScorecardResult: &scpkg.ScorecardResult{
+					Checks: []checker.CheckResult{
+						{Name: checks.CheckCodeReview, Score: 2, Reason: "few reviews"},
+						{Name: checks.CheckLicense, Score: 10, Reason: "license found"},
+					},
+				},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if err := DependencydiffResultsAsSARIF(results, checkDocs, policy, &buf); err != nil {
+		t.Fatalf("DependencydiffResultsAsSARIF: %v", err)
+	}
+	var got sarif210
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if len(got.Runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(got.Runs))
+	}
+	run := got.Runs[0]
+	// Both checks run and the vulnerability policy rule.
+	if len(run.Tool.Driver.Rules) != 3 {
+		t.Errorf("got %d rules, want 3", len(run.Tool.Driver.Rules))
+	}
+	wantResults := []struct {
+		ruleID, level string
+	}{
+		{ruleID: "DependencyVulnerabilityID", level: "error"},
+		{ruleID: "CodeReviewID", level: "warning"},
+	}
+	if len(run.Results) != len(wantResults) {
+		t.Fatalf("got %d results, want %d", len(run.Results), len(wantResults))
+	}
+	for i, want := range wantResults {
+		r := run.Results[i]
+		if r.RuleID != want.ruleID || r.Level != want.level {
+			t.Errorf("result %d: got rule %s at level %s, want rule %s at level %s",
+				i, r.RuleID, r.Level, want.ruleID, want.level)
+		}
+		if run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+			t.Errorf("result %d: rule index %d does not point to rule %s", i, r.RuleIndex, r.RuleID)
+		}
+		if uri := r.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != manifest {
+			t.Errorf("result %d: got location %s, want %s", i, uri, manifest)
+		}
+	}
+}
+
+func TestDependencydiffResultsAsSARIF_DryRun(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	var buf bytes.Buffer
+	policy := &DependencydiffPolicy{FailOnSeverity: High}
+	if err := DependencydiffResultsAsSARIF(dryRunResults(), checkDocs, policy, &buf); err != nil {
+		t.Fatalf("DependencydiffResultsAsSARIF: %v", err)
+	}
+	var got sarif210
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	run := got.Runs[0]
+	statuses := map[string]DependencyStatus{}
+	for _, d := range run.Properties.Dependencies {
+		statuses[d.Name] = d.Status
+	}
+	if !reflect.DeepEqual(statuses, dryRunStatuses) {
+		t.Errorf("got statuses %v, want %v", statuses, dryRunStatuses)
+	}
+	// The vulnerability is reported before the checks would run on the dependency.
+	if len(run.Results) != 1 || run.Results[0].Properties.Status != StatusPlanned {
+		t.Errorf("got results %+v, want a result of a planned dependency", run.Results)
+	}
+}
@@ -0,0 +1,256 @@
+package pkg
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/ossf/scorecard/v4/checker"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	sce "github.com/ossf/scorecard/v4/errors"
+	"github.com/ossf/scorecard/v4/log"
+)
+
+// ReportView is the view model of dependency-diff results which report templates are executed against.
+type ReportView struct {
+	// Added, Updated and Removed are the changes of each change type, sorted by descending aggregate scores.
+	Added   []ChangeView
+	Updated []ChangeView
+	Removed []ChangeView
+
+	// Checks are the sorted names of the checks run on any of the dependencies.
+	Checks []string
+
+	// Evaluation is the evaluation of the dependency-diff policy on the results.
+	Evaluation *PolicyEvaluation
+}
+
+// ChangeView is a dependency change in a ReportView.
+type ChangeView struct {
+	// Change is the dependency change, pairing the dependency at BASE and at HEAD.
+	Change DependencyChange
+
+	// Dependency is the dependency at HEAD, or at BASE if it is removed.
+	Dependency *DependencyCheckResult
+
+	ChangeType   ChangeType
+	Name         string
+	Ecosystem    string
+	ManifestPath string
+	License      string
+	Status       DependencyStatus
+
+	// OldVersion is empty for an added dependency, and NewVersion is empty for a removed one.
+	OldVersion string
+	NewVersion string
+
+	// Score is the aggregate score of Dependency, which is checker.InconclusiveResultScore if it is unknown.
+	Score float64
+
+	// ScoreDelta is the change of the aggregate score of an updated dependency, which is nil unless the
+	// aggregate scores of both versions are known.
+	ScoreDelta *float64
+
+	// Checks are the results of the checks run on Dependency.
+	Checks []checker.CheckResult
+
+	// Error is the error of running the checks on Dependency, which is empty if they succeeded.
+	Error string
+
+	// Vulnerabilities are the vulnerabilities of Dependency, while IntroducedVulnerabilities and
+	// FixedVulnerabilities are those introduced and fixed by the change.
+	Vulnerabilities           []Vulnerability
+	IntroducedVulnerabilities []Vulnerability
+	FixedVulnerabilities      []Vulnerability
+
+	// FixSuggestion is the version suggested to fix the vulnerabilities of Dependency, which can be nil.
+	FixSuggestion *FixSuggestion
+}
+
+// NewReportView builds the view model of dependency-diff results, weighting aggregate scores by the policy.
+func NewReportView(
+	depdiffResults []DependencyCheckResult, checkDocs docs.Doc, policy *DependencydiffPolicy,
+) (*ReportView, error) {
+	evaluation, err := EvaluateDependencydiffPolicy(depdiffResults, policy, checkDocs)
+	if err != nil {
+		return nil, err
+	}
+	view := &ReportView{Checks: []string{}, Evaluation: evaluation}
+	seen := map[string]bool{}
+	for _, c := range PairDependencyChanges(depdiffResults) {
+		cv := newChangeView(c, evaluation, checkDocs, policy)
+		for _, check := range cv.Checks {
+			if !seen[check.Name] {
+				seen[check.Name] = true
+				view.Checks = append(view.Checks, check.Name)
+			}
+		}
+		switch c.ChangeType {
+		case Added:
+			view.Added = append(view.Added, cv)
+		case Updated:
+			view.Updated = append(view.Updated, cv)
+		case Removed:
+			view.Removed = append(view.Removed, cv)
+		}
+	}
+	sort.Strings(view.Checks)
+	for _, changes := range [][]ChangeView{view.Added, view.Updated, view.Removed} {
+		changes := changes
+		sort.SliceStable(changes, func(i, j int) bool { return changes[i].Score > changes[j].Score })
+	}
+	return view, nil
+}
+
+func newChangeView(
+	c DependencyChange, evaluation *PolicyEvaluation, checkDocs docs.Doc, policy *DependencydiffPolicy,
+) ChangeView {
+	d := c.New
+	if d == nil {
+		d = c.Old
+	}
+	cv := ChangeView{
+		Change:          c,
+		Dependency:      d,
+		ChangeType:      c.ChangeType,
+		Name:            d.Name,
+		Ecosystem:       derefString(d.Ecosystem),
+		ManifestPath:    derefString(d.ManifestPath),
+		License:         derefString(d.License),
+		Status:          d.Status,
+		Score:           AggregateScoreOf(d, checkDocs, policy),
+		Vulnerabilities: d.Vulnerabilities,
+		FixSuggestion:   d.FixSuggestion,
+	}
+	if c.Old != nil {
+		cv.OldVersion = derefString(c.Old.Version)
+	}
+	if c.New != nil {
+		cv.NewVersion = derefString(c.New.Version)
+	}
+	if c.Old != nil && c.New != nil {
+		old := AggregateScoreOf(c.Old, checkDocs, policy)
+		if old != checker.InconclusiveResultScore && cv.Score != checker.InconclusiveResultScore {
+			delta := cv.Score - old
+			cv.ScoreDelta = &delta
+		}
+	}
+	if scResult := d.ScorecardResultWithError.ScorecardResult; scResult != nil {
+		cv.Checks = scResult.Checks
+	}
+	if err := d.ScorecardResultWithError.Error; err != nil {
+		cv.Error = err.Error()
+	}
+	for _, dv := range evaluation.Vulnerabilities.Introduced {
+		if dv.Dependency == c.New {
+			cv.IntroducedVulnerabilities = append(cv.IntroducedVulnerabilities, dv.Vulnerability)
+		}
+	}
+	for _, dv := range evaluation.Vulnerabilities.Fixed {
+		if dv.Dependency == c.Old {
+			cv.FixedVulnerabilities = append(cv.FixedVulnerabilities, dv.Vulnerability)
+		}
+	}
+	return cv
+}
+
+// TemplateFuncs are the functions available to report templates:
+//   - score formats an aggregate score with one decimal, or "?" if it is inconclusive.
+//   - delta formats a score delta with its sign, or "" if it is nil.
+//   - check looks up the result of a check by name in a ChangeView, which is nil if the check was not run.
+//   - checkScore formats the score of a check result, "?" if it is inconclusive, or "-" if it is nil.
+//   - checkDocURL gives the documentation URL of a check by name.
+//   - details formats the details of a check result at the log level.
+//   - join joins strings with a separator.
+func TemplateFuncs(checkDocs docs.Doc, logLevel log.Level) map[string]interface{} {
+	return map[string]interface{}{
+		"score": func(score float64) string {
+			if score == checker.InconclusiveResultScore {
+				return "?"
+			}
+			return fmt.Sprintf("%.1f", score)
+		},
+		"delta": func(delta *float64) string {
+			if delta == nil {
+				return ""
+			}
+			return fmt.Sprintf("%+.1f", *delta)
+		},
+		"check": func(cv ChangeView, name string) *checker.CheckResult {
+			for i := range cv.Checks {
+				if cv.Checks[i].Name == name {
+					return &cv.Checks[i]
+				}
+			}
+			return nil
+		},
+		"checkScore": func(c *checker.CheckResult) string {
+			switch {
+			case c == nil:
+				return "-"
+			case c.Score == checker.InconclusiveResultScore:
+				return "?"
+			default:
+				return fmt.Sprintf("%d", c.Score)
+			}
+		},
+		"checkDocURL": func(name string) string {
+			doc, err := checkDocs.GetCheck(name)
+			if err != nil {
+				return ""
+			}
+			return doc.GetDocumentationURL("")
+		},
+		"details": func(c *checker.CheckResult) []string {
+			details := []string{}
+			if c == nil {
+				return details
+			}
+			for i := range c.Details {
+				if m := DetailToString(&c.Details[i], logLevel); m != "" {
+					details = append(details, m)
+				}
+			}
+			return details
+		},
+		"join": strings.Join,
+	}
+}
+
+// DependencydiffResultsAsTemplate executes a template file against the ReportView of dependencydiff results.
+// The template is parsed as an html/template if the file name ends with .html, or as a text/template otherwise.
+func DependencydiffResultsAsTemplate(depdiffResults []DependencyCheckResult,
+	logLevel log.Level, checkDocs docs.Doc, policy *DependencydiffPolicy, templateFile string, writer io.Writer,
+) error {
+	if templateFile == "" {
+		return sce.WithMessage(sce.ErrScorecardInternal, "a template file is required for the template format")
+	}
+	view, err := NewReportView(depdiffResults, checkDocs, policy)
+	if err != nil {
+		return err
+	}
+	funcs := TemplateFuncs(checkDocs, logLevel)
+	name := filepath.Base(templateFile)
+	if strings.HasSuffix(templateFile, ".html") {
+		tmpl, err := htmltemplate.New(name).Funcs(funcs).ParseFiles(templateFile)
+		if err != nil {
+			return sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("template.ParseFiles: %v", err))
+		}
+		if err := tmpl.Execute(writer, view); err != nil {
+			return sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("template.Execute: %v", err))
+		}
+		return nil
+	}
+	tmpl, err := template.New(name).Funcs(funcs).ParseFiles(templateFile)
+	if err != nil {
+		return sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("template.ParseFiles: %v", err))
+	}
+	if err := tmpl.Execute(writer, view); err != nil {
+		return sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("template.Execute: %v", err))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ossf/scorecard/v4/checker"
+	"github.com/ossf/scorecard/v4/checks"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+	"github.com/ossf/scorecard/v4/log"
+	scpkg "github.com/ossf/scorecard/v4/pkg"
+)
+
+func TestDependencydiffResultsAsTemplate(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	added, removed := Added, Removed
+	oldVersion, newVersion, manifest := "1.0.0", "2.0.0", "go.mod"
+	scored := func(score int) ScorecardResultWithError {
+		return ScorecardResultWithError{
+			ScorecardResult: &scpkg.ScorecardResult{
+				Checks: []checker.CheckResult{
+					{
+						Name: checks.CheckLicense, Score: score, Reason: "license",
+						Details: []checker.CheckDetail{
+							{Type: checker.DetailWarn, Msg: checker.LogMessage{Text: "no license file"}},
+						},
+					},
+				},
+			},
+		}
+	}
+	results := []DependencyCheckResult{
+		{
+			Name: "lib", ChangeType: &removed, Version: &oldVersion, ManifestPath: &manifest,
+			ScorecardResultWithError: scored(4),
+			Vulnerabilities:          []Vulnerability{{ID: "GHSA-old", Severity: High}},
+		},
+		{
+			Name: "lib", ChangeType: &added, Version: &newVersion, ManifestPath: &manifest,
+			ScorecardResultWithError: scored(10),
+			Vulnerabilities:          []Vulnerability{{ID: "GHSA-new", Severity: Low, Title: "a<b>&c"}},
+		},
+		{Name: "new", ChangeType: &added, Version: &newVersion, Status: StatusSkippedNoSource},
+	}
+
+	//nolint
+	tests := []struct {
+		name     string
+		file     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "text",
+			file: "report.tmpl",
+			template: `{{$checks := .Checks}}{{range .Updated}}{{.Name}} {{.OldVersion}}->{{.NewVersion}} ` +
+				`{{score .Score}} ({{delta .ScoreDelta}}){{$cv := .}}{{range $checks}} {{.}}={{checkScore (check $cv .)}}` +
+				` [{{join (details (check $cv .)) "; "}}]{{end}}` +
+				` +{{len .IntroducedVulnerabilities}} -{{len .FixedVulnerabilities}}{{end}}` +
+				`{{range .Added}} {{.Name}}:{{score .Score}}:{{.Status}}{{end}}`,
+			want: "lib 1.0.0->2.0.0 10.0 (+6.0) License=10 [Warn: no license file]" +
+				" +1 -1 new:?:skipped-no-source",
+		},
+		{
+			name: "html",
+			file: "report.html",
+			template: `{{range .Updated}}<b>{{.Name}}</b>{{end}}` +
+				`{{range .Evaluation.Vulnerabilities.Introduced}}<i>{{.Vulnerability.ID}} {{.Vulnerability.Title}}</i>{{end}}`,
+			want: "<b>lib</b><i>GHSA-new a&lt;b&gt;&amp;c</i>",
+		},
+		{
+			name:     "unknown function",
+			file:     "report.tmpl",
+			template: `{{range .Added}}{{unknown .Name}}{{end}}`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.template), 0o600); err != nil {
+				t.Fatalf("os.WriteFile: %v", err)
+			}
+			var buf bytes.Buffer
+			err := DependencydiffResultsAsTemplate(results, log.DefaultLevel, checkDocs, nil, path, &buf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
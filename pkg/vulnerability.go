@@ -29,6 +29,37 @@ func (sl *SeverityLevel) IsValid() bool {
 	}
 }
 
+// severityRanks orders severity levels. MEDIUM and MODERATE are the same level named differently by sources.
+var severityRanks = map[SeverityLevel]int{
+	Critical: 4,
+	High:     3,
+	Medium:   2,
+	Moderate: 2,
+	Low:      1,
+	None:     0,
+}
+
+// IsUnknown determines if a SeverityLevel is unknown, which includes an empty or unsupported level.
+func (sl *SeverityLevel) IsUnknown() bool {
+	_, known := severityRanks[*sl]
+	return !known
+}
+
+// IsThreshold determines if a SeverityLevel can be used as a threshold, which excludes the unknown and NONE
+// levels as every severity level is at least them.
+func (sl *SeverityLevel) IsThreshold() bool {
+	return severityRanks[*sl] > severityRanks[None]
+}
+
+// AtLeast determines if a SeverityLevel is at or above the threshold. An unknown severity level is at least
+// any threshold, so that an unclassified vulnerability is not taken for a harmless one.
+func (sl *SeverityLevel) AtLeast(threshold SeverityLevel) bool {
+	if sl.IsUnknown() {
+		return true
+	}
+	return severityRanks[*sl] >= severityRanks[threshold]
+}
+
 // Source is the source of a vulnerability.
 type Source string
 
@@ -79,4 +110,16 @@ type Vulnerability struct {
 
 	// DisclosedTime is the time when the vulenrability is publicly disclosed.
 	DisclosedTime time.Time `json:"disclosed_time" bigquery:"Disclosed"`
+
+	// FixedVersion is the minimal version of the dependency fixing the vulnerability, if known.
+	FixedVersion string `json:"fixed_version" bigquery:"-"`
+}
+
+// FixSuggestion is a version suggested to fix the known vulnerabilities of a dependency.
+type FixSuggestion struct {
+	// Version is the minimal version fixing all the vulnerabilities of which a fix is known.
+	Version string
+
+	// SameMajor indicates whether Version is within the same major version line as the current version.
+	SameMajor bool
 }
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"strings"
 
 	"github.com/aidenwang9867/depdiffvis/pkg"
 	"github.com/google/go-github/v38/github"
@@ -46,10 +47,45 @@ type dependency struct {
 	// Version is the package version of the dependency.
 	Version *string `json:"version"`
 
+	// License is the declared SPDX license expression of the dependency at Version.
+	License *string `json:"license"`
+
+	// Vulnerabilities is a list of known vulnerabilities of the dependency at Version.
+	Vulnerabilities []dependencyVulnerability `json:"vulnerabilities"`
+
 	// Name is the name of the dependency.
 	Name string `json:"name"`
 }
 
+// dependencyVulnerability is a vulnerability of a dependency as returned by the GitHub Dependency Review API,
+// which names the severity level determined by GitHub severity.
+type dependencyVulnerability struct {
+	pkg.Vulnerability
+
+	// Severity is the severity level determined by GitHub, such as critical, high, moderate and low.
+	Severity string `json:"severity"`
+}
+
+// normalizeVulnerabilities converts the vulnerabilities decoded from the GitHub Dependency Review API, whose
+// severity levels are lowercase, to pkg.Vulnerability.
+func normalizeVulnerabilities(vulns []dependencyVulnerability) []pkg.Vulnerability {
+	if len(vulns) == 0 {
+		return nil
+	}
+	results := make([]pkg.Vulnerability, 0, len(vulns))
+	for _, v := range vulns {
+		severity := pkg.SeverityLevel(strings.ToUpper(v.Severity))
+		if !severity.IsValid() {
+			severity = pkg.Unknown
+		}
+		v.Vulnerability.Source = string(pkg.GHSA)
+		v.Vulnerability.Severity = severity
+		v.Vulnerability.GitHubSeverity = severity
+		results = append(results, v.Vulnerability)
+	}
+	return results
+}
+
 // fetchRawDependencyDiffData fetches the dependency-diffs between the two code commits
 // using the GitHub Dependency Review API, and returns a slice of DependencyCheckResult.
 func fetchRawDependencyDiffData(dCtx *dependencydiffContext) error {
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+)
+
+// dependencyReviewResponse is a response of the GitHub Dependency Review API, as documented at
+// https://docs.github.com/en/rest/dependency-graph/dependency-review.
+const dependencyReviewResponse = `[
+  {
+    "change_type": "removed",
+    "manifest": "package.json",
+    "ecosystem": "npm",
+    "name": "helmet",
+    "version": "4.6.0",
+    "package_url": "pkg:npm/helmet@4.6.0",
+    "license": "MIT",
+    "source_repository_url": "https://github.com/helmetjs/helmet",
+    "vulnerabilities": []
+  },
+  {
+    "change_type": "added",
+    "manifest": "package.json",
+    "ecosystem": "npm",
+    "name": "helmet",
+    "version": "5.0.0",
+    "package_url": "pkg:npm/helmet@5.0.0",
+    "license": "MIT",
+    "source_repository_url": "https://github.com/helmetjs/helmet",
+    "vulnerabilities": []
+  },
+  {
+    "change_type": "added",
+    "manifest": "Gemfile",
+    "ecosystem": "rubygems",
+    "name": "ruby-openid",
+    "version": "2.7.0",
+    "package_url": "pkg:gem/ruby-openid@2.7.0",
+    "license": null,
+    "source_repository_url": "https://github.com/openid/ruby-openid",
+    "vulnerabilities": [
+      {
+        "severity": "critical",
+        "advisory_ghsa_id": "GHSA-fqfj-cmh6-hj49",
+        "advisory_summary": "Ruby OpenID",
+        "advisory_url": "https://github.com/advisories/GHSA-fqfj-cmh6-hj49"
+      },
+      {
+        "severity": "unclassified",
+        "advisory_ghsa_id": "GHSA-xxxx-xxxx-xxxx",
+        "advisory_summary": "Unclassified",
+        "advisory_url": "https://github.com/advisories/GHSA-xxxx-xxxx-xxxx"
+      }
+    ]
+  }
+]`
+
+func TestDecodeDependencyReviewResponse(t *testing.T) {
+	t.Parallel()
+	var deps []dependency
+	if err := json.Unmarshal([]byte(dependencyReviewResponse), &deps); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if len(deps) != 3 {
+		t.Fatalf("got %d dependencies, want 3", len(deps))
+	}
+	if got := normalizeVulnerabilities(deps[0].Vulnerabilities); got != nil {
+		t.Errorf("got vulnerabilities %v for helmet, want none", got)
+	}
+	want := []pkg.Vulnerability{
+		{
+			Source:         "GHSA",
+			ID:             "GHSA-fqfj-cmh6-hj49",
+			SourceURL:      "https://github.com/advisories/GHSA-fqfj-cmh6-hj49",
+			Title:          "Ruby OpenID",
+			Severity:       pkg.Critical,
+			GitHubSeverity: pkg.Critical,
+		},
+		{
+			Source:         "GHSA",
+			ID:             "GHSA-xxxx-xxxx-xxxx",
+			SourceURL:      "https://github.com/advisories/GHSA-xxxx-xxxx-xxxx",
+			Title:          "Unclassified",
+			Severity:       pkg.Unknown,
+			GitHubSeverity: pkg.Unknown,
+		},
+	}
+	if got := normalizeVulnerabilities(deps[2].Vulnerabilities); !reflect.DeepEqual(got, want) {
+		t.Errorf("got vulnerabilities %+v, want %+v", got, want)
+	}
+	if deps[2].License != nil {
+		t.Errorf("got license %s, want none", *deps[2].License)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	"github.com/ossf/scorecard/v4/checker"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+)
+
+// ANSI escape codes of the colors of the terminal table.
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+)
+
+// tableColumns are the columns of the terminal table.
+var tableColumns = []string{"CHANGE", "ECOSYSTEM", "NAME", "VERSION", "SCORE", "VULNS", "STATUS"}
+
+// isColorTerminal determines if a file is a terminal which colors can be printed to, unless disabled by the
+// NO_COLOR environment variable.
+func isColorTerminal(f *os.File) bool {
+	if _, disabled := os.LookupEnv("NO_COLOR"); disabled {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// printDependencyTable prints the dependency changes as a table with aligned columns, followed by a summary
+// of the counts per change type and the policy verdict. Scores and the verdict are colored by risk if color
+// is true.
+func printDependencyTable(
+	w io.Writer, results []pkg.DependencyCheckResult, checkDocs docs.Doc, policy *pkg.DependencydiffPolicy,
+	color bool,
+) error {
+	view, err := pkg.NewReportView(results, checkDocs, policy)
+	if err != nil {
+		return fmt.Errorf("error building the report: %w", err)
+	}
+	rows := [][]string{}
+	colors := [][]string{}
+	for _, changes := range [][]pkg.ChangeView{view.Added, view.Updated, view.Removed} {
+		for _, cv := range changes {
+			version := cv.NewVersion
+			switch {
+			case cv.OldVersion != "" && cv.NewVersion != "":
+				version = cv.OldVersion + " → " + cv.NewVersion
+			case cv.NewVersion == "":
+				version = cv.OldVersion
+			}
+			score, scoreColor := "-", ""
+			if cv.Score != checker.InconclusiveResultScore {
+				score, scoreColor = fmt.Sprintf("%.1f", cv.Score), scoreToColor(cv.Score)
+			}
+			vulns, vulnsColor := fmt.Sprintf("%d", len(cv.Vulnerabilities)), ""
+			if len(cv.Vulnerabilities) > 0 {
+				vulnsColor = colorRed
+			}
+			rows = append(rows, []string{
+				string(cv.ChangeType), orDash(cv.Ecosystem), cv.Name, orDash(version), score, vulns,
+				orDash(string(cv.Status)),
+			})
+			colors = append(colors, []string{"", "", "", "", scoreColor, vulnsColor, ""})
+		}
+	}
+	widths := make([]int, len(tableColumns))
+	for i, c := range tableColumns {
+		widths[i] = utf8.RuneCountInString(c)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString(tableRow(tableColumns, nil, widths, color))
+	for i, row := range rows {
+		sb.WriteString(tableRow(row, colors[i], widths, color))
+	}
+	verdict := string(view.Evaluation.Verdict)
+	if color {
+		verdict = verdictToColor(view.Evaluation.Verdict) + verdict + colorReset
+	}
+	sb.WriteString(fmt.Sprintf(
+		"\n%d added, %d updated, %d removed. Policy verdict: %s (%d violations)\n",
+		len(view.Added), len(view.Updated), len(view.Removed), verdict, len(view.Evaluation.Violations),
+	))
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("error printing the table: %w", err)
+	}
+	return nil
+}
+
+// tableRow pads the cells of a row to the widths of the columns, coloring them after padding so that the
+// escape codes do not count in the widths.
+func tableRow(cells, colors []string, widths []int, color bool) string {
+	padded := make([]string, len(cells))
+	for i, cell := range cells {
+		padded[i] = cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		if color && colors != nil && colors[i] != "" {
+			padded[i] = colors[i] + padded[i] + colorReset
+		}
+	}
+	return strings.TrimRight(strings.Join(padded, "  "), " ") + "\n"
+}
+
+func scoreToColor(score float64) string {
+	switch {
+	case score >= 7:
+		return colorGreen
+	case score >= 4:
+		return colorYellow
+	default:
+		return colorRed
+	}
+}
+
+func verdictToColor(v pkg.Verdict) string {
+	switch v {
+	case pkg.VerdictFail:
+		return colorRed
+	case pkg.VerdictWarn:
+		return colorYellow
+	default:
+		return colorGreen
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
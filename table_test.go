@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aidenwang9867/depdiffvis/pkg"
+	docs "github.com/ossf/scorecard/v4/docs/checks"
+)
+
+var (
+	tableColumnSeparator = regexp.MustCompile(` {2,}`)
+	tableColoredCell     = regexp.MustCompile(`(\033\[\d+m)([^\033]*)` + regexp.QuoteMeta(colorReset))
+)
+
+// tableCell is the text of a colored cell of a printed table, along with its color.
+type tableCell struct {
+	color, text string
+}
+
+// parseDependencyTable parses the rows of a printed table, without colors, and its summary line.
+func parseDependencyTable(t *testing.T, table string) ([][]string, string) {
+	t.Helper()
+	table = tableColoredCell.ReplaceAllString(table, "$2")
+	body, summary, found := strings.Cut(table, "\n\n")
+	if !found {
+		t.Fatalf("got no summary in %q", table)
+	}
+	rows := [][]string{}
+	for _, line := range strings.Split(body, "\n") {
+		rows = append(rows, tableColumnSeparator.Split(strings.TrimRight(line, " "), -1))
+	}
+	return rows, strings.TrimSuffix(summary, "\n")
+}
+
+func TestPrintDependencyTable(t *testing.T) {
+	t.Parallel()
+	checkDocs, err := docs.Read()
+	if err != nil {
+		t.Fatalf("docs.Read: %v", err)
+	}
+	results := testResults()
+	policy := &pkg.DependencydiffPolicy{FailOnSeverity: pkg.High}
+	wantRows := [][]string{
+		{"CHANGE", "ECOSYSTEM", "NAME", "VERSION", "SCORE", "VULNS", "STATUS"},
+		{"added", "npm", "vulnerable", "1.0.0", "0.0", "1", "evaluated"},
+		{"added", "npm", "unreachable", "1.0.0", "-", "0", "failed"},
+		{"added", "npm", `=HYPERLINK("<script>")`, "1.0.0", "-", "0", "skipped-no-source"},
+		{"updated", "Go", "lib", "1.0.0 → 2.0.0", "9.0", "0", "evaluated"},
+		{"removed", "npm", "gone", "1.0.0", "-", "0", "skipped-change-type"},
+	}
+	wantSummary := "3 added, 1 updated, 1 removed. Policy verdict: fail (1 violations)"
+	//nolint
+	tests := []struct {
+		name      string
+		color     bool
+		wantCells []tableCell
+	}{
+		{
+			name:  "plain",
+			color: false,
+		},
+		{
+			name:  "color",
+			color: true,
+			// The scores, the vulnerabilities and the verdict are colored.
+			wantCells: []tableCell{
+				{color: colorRed, text: "0.0"},
+				{color: colorRed, text: "1"},
+				{color: colorGreen, text: "9.0"},
+				{color: colorRed, text: "fail"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := printDependencyTable(&buf, results, checkDocs, policy, tt.color); err != nil {
+				t.Fatalf("printDependencyTable: %v", err)
+			}
+			rows, summary := parseDependencyTable(t, buf.String())
+			if !reflect.DeepEqual(rows, wantRows) {
+				t.Errorf("got rows\n%q\nwant\n%q", rows, wantRows)
+			}
+			if summary != wantSummary {
+				t.Errorf("got summary %q, want %q", summary, wantSummary)
+			}
+			var cells []tableCell
+			for _, m := range tableColoredCell.FindAllStringSubmatch(buf.String(), -1) {
+				cells = append(cells, tableCell{color: m[1], text: strings.TrimSpace(m[2])})
+			}
+			if !reflect.DeepEqual(cells, tt.wantCells) {
+				t.Errorf("got colored cells %q, want %q", cells, tt.wantCells)
+			}
+			if !tt.color && strings.Contains(buf.String(), "\033") {
+				t.Errorf("got escape codes without color")
+			}
+		})
+	}
+}